@@ -0,0 +1,333 @@
+// Command transcriboctl is the headless companion to the transcribo GUI: it
+// reuses internal/scriber to transcribe files, directories, or globs from
+// the shell, and talks to a running transcribo daemon over its Unix socket
+// when one is available so long jobs started in the terminal show up in
+// the GUI too.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/alesr/httpclient"
+	"github.com/alesr/transcribo/internal/backendcfg"
+	"github.com/alesr/transcribo/internal/daemon"
+	"github.com/alesr/transcribo/internal/scriber"
+	"github.com/alesr/transcribo/internal/watch"
+)
+
+// watchExts mirrors app.validExts: the set of media extensions that trigger
+// auto-ingest in watch mode.
+var watchExts = map[string]struct{}{
+	".mp4":  {},
+	".mp3":  {},
+	".wav":  {},
+	".webm": {},
+	".avi":  {},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "transcribe":
+		if err := runTranscribe(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "transcriboctl:", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: transcriboctl transcribe [--lang en] [--format srt] [--recursive] [--watch] path...")
+}
+
+// progressEvent is the JSON line transcriboctl writes to stdout per job
+// update.
+type progressEvent struct {
+	Path     string  `json:"path"`
+	Stage    string  `json:"stage"`
+	Fraction float64 `json:"fraction,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+func runTranscribe(args []string) error {
+	fs := flag.NewFlagSet("transcribe", flag.ExitOnError)
+	lang := fs.String("lang", "en", "language code passed to the transcription backend")
+	format := fs.String("format", "srt", "output format: srt, vtt, json, verbose_json, text")
+	recursive := fs.Bool("recursive", false, "descend into subdirectories")
+	watchFlag := fs.Bool("watch", false, "keep running and auto-ingest files created under the given directories")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	runner, err := newRunner(*lang, *format)
+	if err != nil {
+		return err
+	}
+	defer runner.close()
+
+	seen := make(map[string]struct{})
+	enc := json.NewEncoder(os.Stdout)
+
+	submit := func(path string) {
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+
+		jobID, err := runner.enqueue(path)
+		if err != nil {
+			_ = enc.Encode(progressEvent{Path: path, Stage: "failed", Error: err.Error()})
+			return
+		}
+		runner.track(jobID, path)
+	}
+
+	for _, root := range fs.Args() {
+		paths, err := expandPath(root, *recursive)
+		if err != nil {
+			return fmt.Errorf("expanding %s: %w", root, err)
+		}
+		for _, p := range paths {
+			submit(p)
+		}
+	}
+
+	if *watchFlag {
+		return watchMode(fs.Args(), *recursive, submit, runner, enc)
+	}
+
+	return drain(runner, enc)
+}
+
+// drain streams events until every submitted job has reached a terminal
+// state, then returns.
+func drain(r *runner, enc *json.Encoder) error {
+	pending := r.pendingCount()
+	for pending > 0 {
+		event, ok := <-r.events()
+		if !ok {
+			return nil
+		}
+		path, terminal := r.handle(event, enc)
+		if terminal && path != "" {
+			pending--
+		}
+	}
+	return nil
+}
+
+// watchMode keeps running, auto-ingesting files fsnotify reports under any
+// directory in roots, until interrupted. Non-directory roots were already
+// handled by the initial scan in runTranscribe and are ignored here.
+func watchMode(roots []string, recursive bool, submit func(string), r *runner, enc *json.Encoder) error {
+	w, err := watch.New(slog.Default(), watchExts)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if err := w.Add(root, recursive); err != nil {
+			return fmt.Errorf("watching %s: %w", root, err)
+		}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	for {
+		select {
+		case path := <-w.Files():
+			submit(path)
+		case event, ok := <-r.events():
+			if !ok {
+				return nil
+			}
+			r.handle(event, enc)
+		case <-sigCh:
+			return nil
+		}
+	}
+}
+
+func expandPath(root string, recursive bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		// Not a literal path; try it as a glob.
+		matches, globErr := filepath.Glob(root)
+		if globErr != nil || len(matches) == 0 {
+			return nil, err
+		}
+		return matches, nil
+	}
+
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var paths []string
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		full := filepath.Join(root, entry.Name())
+		if entry.IsDir() {
+			if recursive {
+				sub, err := expandPath(full, recursive)
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, sub...)
+			}
+			continue
+		}
+		paths = append(paths, full)
+	}
+	return paths, nil
+}
+
+func eventToProgress(path string, event scriber.Event) progressEvent {
+	stage := string(event.Type)
+	if event.Type == scriber.EventProgress {
+		stage = event.Stage
+	}
+	pe := progressEvent{Path: path, Stage: stage, Fraction: event.Fraction}
+	if event.Err != nil {
+		pe.Error = event.Err.Error()
+	}
+	return pe
+}
+
+// runner abstracts over talking to a shared daemon queue versus running a
+// private, local one for the lifetime of this invocation.
+type runner struct {
+	lang, format string
+
+	client      *daemon.Client
+	localQueue  *scriber.Queue
+	eventsCh    <-chan scriber.Event
+	jobPathByID map[string]string
+}
+
+func newRunner(lang, format string) (*runner, error) {
+	r := &runner{lang: lang, format: format, jobPathByID: make(map[string]string)}
+
+	if client, err := daemon.Dial(daemon.SocketPath()); err == nil {
+		r.client = client
+		events, err := client.Watch()
+		if err != nil {
+			return nil, fmt.Errorf("watching daemon events: %w", err)
+		}
+		r.eventsCh = events
+		return r, nil
+	}
+
+	queue, err := newLocalQueue()
+	if err != nil {
+		return nil, err
+	}
+	r.localQueue = queue
+	r.localQueue.Start()
+	r.eventsCh = queue.Subscribe()
+	return r, nil
+}
+
+func (r *runner) enqueue(path string) (string, error) {
+	name := filepath.Base(path)
+	if r.client != nil {
+		return r.client.Enqueue(path, name, r.lang, r.format, scriber.PriorityNormal)
+	}
+	in := scriber.NewFileInput(path, name, r.lang, r.format)
+	return r.localQueue.Enqueue(context.Background(), in)
+}
+
+func (r *runner) track(jobID, path string) {
+	r.jobPathByID[jobID] = path
+}
+
+func (r *runner) events() <-chan scriber.Event {
+	return r.eventsCh
+}
+
+func (r *runner) pendingCount() int {
+	return len(r.jobPathByID)
+}
+
+// handle prints event as a progressEvent and returns the path it belonged
+// to ("" for a job this runner didn't submit) and whether the event is
+// terminal, so the caller can stop waiting on that path.
+func (r *runner) handle(event scriber.Event, enc *json.Encoder) (path string, terminal bool) {
+	path, ok := r.jobPathByID[event.JobID]
+	if !ok {
+		return "", false
+	}
+	_ = enc.Encode(eventToProgress(path, event))
+
+	switch event.Type {
+	case scriber.EventCompleted, scriber.EventFailed:
+		delete(r.jobPathByID, event.JobID)
+		return path, true
+	}
+	return path, false
+}
+
+func (r *runner) close() {
+	if r.localQueue != nil {
+		r.localQueue.Stop()
+	}
+}
+
+// newLocalQueue spins up a private queue for this invocation when no
+// daemon is reachable, reusing whatever transcription backend was last
+// configured via the GUI's backend settings dialog.
+func newLocalQueue() (*scriber.Queue, error) {
+	cfgPath, err := backendcfg.Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := backendcfg.Load(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	transcriber, err := backendcfg.NewTranscriber(cfg, os.Getenv("OPENAI_API_KEY"), "whisper-1", httpclient.New(
+		httpclient.WithTimeout(10*time.Minute),
+		httpclient.WithDialerTimeout(10*time.Second),
+		httpclient.WithDialerKeepAlive(30*time.Second),
+		httpclient.WithTLSHandshakeTimeout(10*time.Second),
+		httpclient.WithResponseHeaderTimeout(30*time.Second),
+		httpclient.WithIdleConnTimeout(30*time.Second),
+		httpclient.WithMaxIdleConns(25),
+		httpclient.WithForceHTTP2Disabled(),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("no transcribo daemon running and %w", err)
+	}
+	return scriber.NewQueue(slog.Default(), transcriber, 3, 3, ""), nil
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -21,14 +23,48 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/alesr/transcribo/internal/backendcfg"
+	"github.com/alesr/transcribo/internal/i18n"
 	"github.com/alesr/transcribo/internal/scriber"
+	"github.com/alesr/transcribo/internal/watch"
 )
 
 const (
 	appName     = "transcribo"
 	fileMaxSize = 1024 * 1024 * 1024 // 1 GB
+
+	// processedSubdir is where watched originals are moved once ingested.
+	processedSubdir = "processed"
+
+	watchLanguage   = "en"
+	watchOutputType = "srt"
+
+	// Fallbacks for per-file settings when Preferences has no stored
+	// default yet.
+	defaultLanguage    = "en"
+	defaultOutputType  = "srt"
+	defaultModel       = "whisper-1"
+	defaultTemperature = 0.0
+)
+
+// Preferences keys under which the last-used per-file settings are stored,
+// so the next uploaded file starts from whatever the user picked last.
+const (
+	prefLanguage    = "default_language"
+	prefOutputType  = "default_output_type"
+	prefModel       = "default_model"
+	prefTemperature = "default_temperature"
+	prefTranslate   = "default_translate"
+
+	// prefUILanguage stores the chosen interface locale, as opposed to
+	// prefLanguage which is a per-file transcription setting.
+	prefUILanguage = "ui_language"
 )
 
+// responseFormats are the Whisper response_format values the settings
+// dialog lets a file be overridden to.
+var responseFormats = []string{"srt", "vtt", "json", "verbose_json", "text"}
+
 var (
 	winSize   = windowSize{800, 600}
 	validExts = map[string]struct{}{
@@ -43,8 +79,8 @@ var (
 
 type (
 	scriberSvc interface {
-		Process(ctx context.Context, in scriber.Input) error
-		Collect() <-chan scriber.Output
+		Enqueue(ctx context.Context, in scriber.Input) (string, error)
+		Subscribe() <-chan scriber.Event
 	}
 
 	windowSize struct {
@@ -52,30 +88,58 @@ type (
 	}
 
 	inputFile struct {
-		name       string
-		language   string
-		outputType string
-		data       io.ReadCloser
+		name        string
+		language    string
+		outputType  string
+		model       string
+		prompt      string
+		temperature float64
+		translate   bool
+		data        []byte
 	}
 
 	progressStatus struct {
+		jobID    string
 		filename string
 		stage    string
+		fraction float64
 		err      error
+		output   *scriber.Output
 	}
 )
 
+// progressRow is the UI for one in-flight job: a label showing its stage
+// plus a determinate bar tracking its fraction complete.
+type progressRow struct {
+	box   *fyne.Container
+	bar   *widget.ProgressBar
+	label *widget.Label
+}
+
 type scriberInput struct {
-	name       string
-	language   string
-	outputType string
-	data       io.ReadCloser
+	name        string
+	language    string
+	outputType  string
+	model       string
+	prompt      string
+	temperature float64
+	translate   bool
+	data        []byte
 }
 
-func (in *scriberInput) Name() string        { return in.name }
-func (in *scriberInput) Language() string    { return in.language }
-func (in *scriberInput) OutputType() string  { return in.outputType }
-func (in *scriberInput) Data() io.ReadCloser { return in.data }
+func (in *scriberInput) Name() string         { return in.name }
+func (in *scriberInput) Language() string     { return in.language }
+func (in *scriberInput) OutputType() string   { return in.outputType }
+func (in *scriberInput) Model() string        { return in.model }
+func (in *scriberInput) Prompt() string       { return in.prompt }
+func (in *scriberInput) Temperature() float64 { return in.temperature }
+func (in *scriberInput) Translate() bool      { return in.translate }
+
+// Data returns a fresh reader over in.data every call, since transcribe
+// needs to read it once to probe the duration and again to encode it.
+func (in *scriberInput) Data() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(in.data))
+}
 
 type App struct {
 	mu            sync.RWMutex
@@ -86,6 +150,10 @@ type App struct {
 	window        fyne.Window
 	selectedFiles []inputFile
 	scriberSvc    scriberSvc
+	jobFiles      map[string]string // job id -> source filename
+	progressRows  map[string]*progressRow
+	watcher       *watch.Watcher
+	watchJobPaths map[string]string // job id -> watched source path, for jobs enqueued by the watcher
 	ctx           context.Context
 	cancel        context.CancelFunc
 
@@ -93,11 +161,15 @@ type App struct {
 		mainContent      *fyne.Container
 		fileList         *fyne.Container
 		processContainer *fyne.Container
-		progressBar      *widget.ProgressBarInfinite
+		progressRows     *fyne.Container
 		statusLabel      *widget.TextGrid
 		resultsList      *fyne.Container
 		uploadBtn        *widget.Button
+		watchBtn         *widget.Button
 		processBtn       *widget.Button
+		headerSubtitle   *widget.RichText
+		formatsHint      *widget.RichText
+		resultsHeader    *widget.Label
 		statusCh         chan progressStatus
 	}
 }
@@ -107,6 +179,10 @@ func New(logger *slog.Logger, scriberSvc scriberSvc) *App {
 
 	fApp := fyneapp.NewWithID(appName)
 
+	if lang := fApp.Preferences().StringWithFallback(prefUILanguage, i18n.DetectLanguage()); lang != "" {
+		i18n.SetLanguage(lang)
+	}
+
 	t := guiTheme{variant: theme.VariantDark}
 	fApp.Settings().SetTheme(&t)
 
@@ -117,6 +193,9 @@ func New(logger *slog.Logger, scriberSvc scriberSvc) *App {
 		theme:         t,
 		selectedFiles: make([]inputFile, 0),
 		scriberSvc:    scriberSvc,
+		jobFiles:      make(map[string]string),
+		progressRows:  make(map[string]*progressRow),
+		watchJobPaths: make(map[string]string),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
@@ -146,10 +225,14 @@ func (a *App) Run() {
 
 	a.window.SetCloseIntercept(func() {
 		a.cancel()
+		if a.watcher != nil {
+			a.watcher.Close()
+		}
 		close(a.components.statusCh)
 		a.window.Close()
 	})
 	go a.handleStatus()
+	go a.handleEvents()
 	a.window.ShowAndRun()
 }
 
@@ -158,13 +241,14 @@ func (a *App) initComponents() {
 	a.components.mainContent = container.NewVBox()
 	a.components.fileList = container.NewVBox()
 	a.components.processContainer = container.NewVBox()
-	a.components.progressBar = widget.NewProgressBarInfinite()
+	a.components.progressRows = container.NewVBox()
 	a.components.statusLabel = widget.NewTextGrid()
 	a.components.resultsList = container.NewVBox()
-	a.components.uploadBtn = widget.NewButtonWithIcon("Select Files", theme.FolderOpenIcon(), nil)
-	a.components.processBtn = widget.NewButtonWithIcon("Process Files", theme.ConfirmIcon(), nil)
+	a.components.uploadBtn = widget.NewButtonWithIcon(i18n.T("select_files"), theme.FolderOpenIcon(), nil)
+	a.components.watchBtn = widget.NewButtonWithIcon(i18n.T("watch_folder"), theme.VisibilityIcon(), nil)
+	a.components.processBtn = widget.NewButtonWithIcon(i18n.T("process_files"), theme.ConfirmIcon(), nil)
 
-	a.components.progressBar.Hide()
+	a.components.progressRows.Hide()
 	a.components.statusLabel.Hide()
 	a.components.processContainer.Hide()
 }
@@ -181,31 +265,112 @@ func (a *App) setupTheme() {
 	})
 	themeToggle.Icon = theme.ColorPaletteIcon()
 
-	a.components.mainContent.Add(container.NewHBox(layout.NewSpacer(), themeToggle))
+	langSelect := widget.NewSelect(i18n.SupportedLanguages, func(lang string) {
+		i18n.SetLanguage(lang)
+		a.fyneApp.Preferences().SetString(prefUILanguage, lang)
+		a.refreshLocale()
+	})
+	langSelect.SetSelected(a.fyneApp.Preferences().StringWithFallback(prefUILanguage, i18n.DetectLanguage()))
+
+	backendBtn := widget.NewButtonWithIcon("", theme.StorageIcon(), func() {
+		a.showBackendSettings()
+	})
+
+	a.components.mainContent.Add(container.NewHBox(layout.NewSpacer(), langSelect, backendBtn, themeToggle))
+}
+
+// showBackendSettings lets the user pick which transcription backend to use
+// and configure it. The choice is persisted via backendcfg, the same store
+// main.go and transcriboctl read, and takes effect the next time the app
+// starts, since the scriber.Queue (and the Transcriber backing it) is
+// already built and running by the time this window exists.
+func (a *App) showBackendSettings() {
+	cfgPath, err := backendcfg.Path()
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	cfg, err := backendcfg.Load(cfgPath)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+
+	backendSelect := widget.NewSelect(backendcfg.Names, nil)
+	if cfg.Backend == "" {
+		cfg.Backend = backendcfg.OpenAI
+	}
+	backendSelect.SetSelected(cfg.Backend)
+
+	binEntry := widget.NewEntry()
+	binEntry.SetText(cfg.WhisperCPPBinPath)
+	binEntry.SetPlaceHolder("whisper-cli")
+
+	modelEntry := widget.NewEntry()
+	modelEntry.SetText(cfg.WhisperCPPModelPath)
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetText(cfg.OpenAICompatBaseURL)
+
+	keyEntry := widget.NewPasswordEntry()
+	keyEntry.SetText(cfg.OpenAICompatAPIKey)
+
+	dialog.ShowForm("Transcription backend (applies on restart)", i18n.T("action_save"), i18n.T("action_cancel"), []*widget.FormItem{
+		widget.NewFormItem("Backend", backendSelect),
+		widget.NewFormItem("whisper.cpp binary", binEntry),
+		widget.NewFormItem("whisper.cpp model path", modelEntry),
+		widget.NewFormItem("OpenAI-compatible base URL", urlEntry),
+		widget.NewFormItem("OpenAI-compatible API key", keyEntry),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := backendcfg.Save(cfgPath, backendcfg.Config{
+			Backend:             backendSelect.Selected,
+			WhisperCPPBinPath:   binEntry.Text,
+			WhisperCPPModelPath: modelEntry.Text,
+			OpenAICompatBaseURL: urlEntry.Text,
+			OpenAICompatAPIKey:  keyEntry.Text,
+		}); err != nil {
+			dialog.ShowError(err, a.window)
+		}
+	}, a.window)
+}
+
+// refreshLocale re-sets the text of widgets that were given their label at
+// construction time, so a language change is reflected immediately instead
+// of only on the next restart.
+func (a *App) refreshLocale() {
+	a.components.uploadBtn.SetText(i18n.T("select_files"))
+	a.components.watchBtn.SetText(i18n.T("watch_folder"))
+	a.components.processBtn.SetText(i18n.T("process_files"))
+	a.components.headerSubtitle.ParseMarkdown("## " + i18n.T("app_subtitle"))
+	a.components.formatsHint.ParseMarkdown(i18n.T("supported_formats"))
+	a.components.resultsHeader.SetText(i18n.T("results_header"))
 }
 
 func (a *App) setupHeader() {
 	headerTitle := widget.NewRichTextFromMarkdown("# " + appName)
-	headerSubtitle := widget.NewRichTextFromMarkdown("## Generate subtitles and transcriptions")
+	a.components.headerSubtitle = widget.NewRichTextFromMarkdown("## " + i18n.T("app_subtitle"))
 
 	header := container.NewVBox(
 		container.NewCenter(headerTitle),
-		container.NewCenter(headerSubtitle),
+		container.NewCenter(a.components.headerSubtitle),
 		widget.NewSeparator(),
 	)
 	a.components.mainContent.Add(header)
 }
 
 func (a *App) setupFileHandling() {
+	a.components.formatsHint = widget.NewRichTextFromMarkdown(i18n.T("supported_formats"))
 	fileSelection := container.NewVBox(
-		container.NewCenter(
-			widget.NewRichTextFromMarkdown("Supported Formats: **MP4, MP3, WAV, WEBM, AVI _(max 1GB)_**"),
-		),
-		container.NewCenter(a.components.uploadBtn),
+		container.NewCenter(a.components.formatsHint),
+		container.NewCenter(container.NewHBox(a.components.uploadBtn, a.components.watchBtn)),
 		widget.NewSeparator(),
 		a.components.fileList,
 	)
 	a.setupUploadButton()
+	a.setupWatchButton()
 	a.components.mainContent.Add(fileSelection)
 }
 
@@ -218,6 +383,9 @@ func (a *App) updateFileList() {
 		f := a.selectedFiles[i]
 		fileRow := container.NewHBox(
 			widget.NewLabel(f.name),
+			widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+				a.showFileSettings(f.name)
+			}),
 			widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
 				a.removeFile(f.name)
 			}),
@@ -234,8 +402,8 @@ func (a *App) updateFileList() {
 }
 
 func (a *App) setupResults() {
-	resultsHeader := widget.NewLabelWithStyle("Results", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
-	a.components.resultsList.Add(resultsHeader)
+	a.components.resultsHeader = widget.NewLabelWithStyle(i18n.T("results_header"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	a.components.resultsList.Add(a.components.resultsHeader)
 	a.components.resultsList.Hide()
 	a.components.mainContent.Add(a.components.resultsList)
 }
@@ -243,7 +411,7 @@ func (a *App) setupResults() {
 func (a *App) setupLayout() {
 	a.components.mainContent.Objects = append(
 		a.components.mainContent.Objects,
-		a.components.progressBar,
+		a.components.progressRows,
 		a.components.statusLabel,
 		widget.NewSeparator(),
 		a.components.resultsList,
@@ -254,6 +422,9 @@ func (a *App) setupShortcuts() {
 	a.window.Canvas().SetOnTypedKey(func(ke *fyne.KeyEvent) {
 		if ke.Name == fyne.KeyEscape {
 			a.cancel()
+			if a.watcher != nil {
+				a.watcher.Close()
+			}
 			close(a.components.statusCh)
 			a.window.Close()
 		}
@@ -269,6 +440,94 @@ func (a *App) removeFile(name string) {
 	a.updateFileList()
 }
 
+// defaultFileSettings returns the per-file settings a newly uploaded file
+// should start with: whatever the user last saved via showFileSettings, or
+// the package defaults if nothing's been saved yet.
+func (a *App) defaultFileSettings() (language, outputType, model string, temperature float64, translate bool) {
+	prefs := a.fyneApp.Preferences()
+	return prefs.StringWithFallback(prefLanguage, defaultLanguage),
+		prefs.StringWithFallback(prefOutputType, defaultOutputType),
+		prefs.StringWithFallback(prefModel, defaultModel),
+		prefs.FloatWithFallback(prefTemperature, defaultTemperature),
+		prefs.Bool(prefTranslate)
+}
+
+// showFileSettings opens a popup letting the user override name's
+// transcription settings. Saving updates the file in place and, for every
+// setting except the prompt, becomes the new default for files uploaded
+// afterwards.
+func (a *App) showFileSettings(name string) {
+	a.mu.RLock()
+	idx := slices.IndexFunc(a.selectedFiles, func(v inputFile) bool { return v.name == name })
+	if idx == -1 {
+		a.mu.RUnlock()
+		return
+	}
+	f := a.selectedFiles[idx]
+	a.mu.RUnlock()
+
+	languageEntry := widget.NewEntry()
+	languageEntry.SetText(f.language)
+
+	formatSelect := widget.NewSelect(responseFormats, nil)
+	formatSelect.SetSelected(f.outputType)
+
+	modelEntry := widget.NewEntry()
+	modelEntry.SetText(f.model)
+
+	promptEntry := widget.NewMultiLineEntry()
+	promptEntry.SetText(f.prompt)
+	promptEntry.SetPlaceHolder(i18n.T("prompt_placeholder"))
+
+	temperatureEntry := widget.NewEntry()
+	temperatureEntry.SetText(strconv.FormatFloat(f.temperature, 'f', -1, 64))
+
+	translateCheck := widget.NewCheck(i18n.T("field_translate"), nil)
+	translateCheck.SetChecked(f.translate)
+
+	dialog.ShowForm(i18n.T("settings_title", name), i18n.T("action_save"), i18n.T("action_cancel"), []*widget.FormItem{
+		widget.NewFormItem(i18n.T("field_language"), languageEntry),
+		widget.NewFormItem(i18n.T("field_format"), formatSelect),
+		widget.NewFormItem(i18n.T("field_model"), modelEntry),
+		widget.NewFormItem(i18n.T("field_prompt"), promptEntry),
+		widget.NewFormItem(i18n.T("field_temperature"), temperatureEntry),
+		widget.NewFormItem("", translateCheck),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+
+		temperature, err := strconv.ParseFloat(temperatureEntry.Text, 64)
+		if err != nil {
+			temperature = defaultTemperature
+		}
+
+		a.applyFileSettings(name, languageEntry.Text, formatSelect.Selected, modelEntry.Text, promptEntry.Text, temperature, translateCheck.Checked)
+	}, a.window)
+}
+
+// applyFileSettings updates the selected file named name and persists the
+// new values, except prompt, as the defaults for the next uploaded file.
+func (a *App) applyFileSettings(name, language, outputType, model, prompt string, temperature float64, translate bool) {
+	a.mu.Lock()
+	if idx := slices.IndexFunc(a.selectedFiles, func(v inputFile) bool { return v.name == name }); idx != -1 {
+		a.selectedFiles[idx].language = language
+		a.selectedFiles[idx].outputType = outputType
+		a.selectedFiles[idx].model = model
+		a.selectedFiles[idx].prompt = prompt
+		a.selectedFiles[idx].temperature = temperature
+		a.selectedFiles[idx].translate = translate
+	}
+	a.mu.Unlock()
+
+	prefs := a.fyneApp.Preferences()
+	prefs.SetString(prefLanguage, language)
+	prefs.SetString(prefOutputType, outputType)
+	prefs.SetString(prefModel, model)
+	prefs.SetFloat(prefTemperature, temperature)
+	prefs.SetBool(prefTranslate, translate)
+}
+
 func (a *App) setupUploadButton() {
 	a.components.uploadBtn.OnTapped = func() {
 		fd := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
@@ -289,28 +548,33 @@ func (a *App) setupUploadButton() {
 			}
 
 			if info.Size() > fileMaxSize {
-				dialog.ShowError(errors.New("file size exceeds 1GB limit"), a.window)
+				dialog.ShowError(errors.New(i18n.T("error_file_too_large")), a.window)
 				return
 			}
 
 			ext := strings.ToLower(filepath.Ext(reader.URI().Path()))
 			if _, ok := validExts[ext]; !ok {
-				dialog.ShowError(errors.New("unsupported file format"), a.window)
+				dialog.ShowError(errors.New(i18n.T("error_unsupported_format")), a.window)
 				return
 			}
 
 			var buf bytes.Buffer
 			if _, err := io.Copy(&buf, reader); err != nil {
-				dialog.ShowError(fmt.Errorf("reading file: %w", err), a.window)
+				dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("error_reading_file"), err), a.window)
 				return
 			}
 
+			language, outputType, model, temperature, translate := a.defaultFileSettings()
+
 			a.mu.Lock()
 			a.selectedFiles = append(a.selectedFiles, inputFile{
-				name:       info.Name(),
-				language:   "en",
-				outputType: "srt",
-				data:       io.NopCloser(bytes.NewReader(buf.Bytes())),
+				name:        info.Name(),
+				language:    language,
+				outputType:  outputType,
+				model:       model,
+				temperature: temperature,
+				translate:   translate,
+				data:        buf.Bytes(),
 			})
 			a.mu.Unlock()
 
@@ -321,6 +585,75 @@ func (a *App) setupUploadButton() {
 	}
 }
 
+func (a *App) setupWatchButton() {
+	a.components.watchBtn.OnTapped = func() {
+		fd := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			if err := a.addWatchDir(uri.Path()); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}, a.window)
+		fd.Show()
+	}
+}
+
+// addWatchDir starts (lazily creating the watcher) or extends watching of
+// dir, auto-enqueueing any media file with an extension in validExts that
+// later appears there.
+func (a *App) addWatchDir(dir string) error {
+	a.mu.Lock()
+	if a.watcher == nil {
+		w, err := watch.New(a.logger, validExts)
+		if err != nil {
+			a.mu.Unlock()
+			return fmt.Errorf("starting folder watcher: %w", err)
+		}
+		a.watcher = w
+		go a.handleWatchedFiles()
+	}
+	watcher := a.watcher
+	a.mu.Unlock()
+
+	return watcher.Add(dir, false)
+}
+
+// handleWatchedFiles enqueues files the watcher reports as stabilized.
+func (a *App) handleWatchedFiles() {
+	for path := range a.watcher.Files() {
+		in := scriber.NewFileInput(path, filepath.Base(path), watchLanguage, watchOutputType)
+
+		jobID, err := a.scriberSvc.Enqueue(a.ctx, in)
+		if err != nil {
+			a.components.statusCh <- progressStatus{filename: filepath.Base(path), stage: "failed", err: err}
+			continue
+		}
+
+		a.mu.Lock()
+		a.jobFiles[jobID] = filepath.Base(path)
+		a.watchJobPaths[jobID] = path
+		a.mu.Unlock()
+	}
+}
+
+// writeWatchedResult saves a completed watched job's transcription next to
+// its source file and moves the source into processedSubdir.
+func (a *App) writeWatchedResult(path string, output *scriber.Output) {
+	dest := filepath.Join(filepath.Dir(path), output.Name)
+	if err := os.WriteFile(dest, output.Text, 0o644); err != nil {
+		a.logger.Error("writing watched result", slog.String("path", dest), slog.Any("error", err))
+		return
+	}
+	if err := watch.MoveProcessed(path, processedSubdir); err != nil {
+		a.logger.Error("moving processed source", slog.String("path", path), slog.Any("error", err))
+	}
+}
+
 func (a *App) handleStatus() {
 	for status := range a.components.statusCh {
 		a.updateStatus(status)
@@ -332,25 +665,144 @@ func (a *App) updateStatus(status progressStatus) {
 	defer a.mu.Unlock()
 
 	if status.err != nil {
-		dialog.ShowError(fmt.Errorf("error processing file %s: %w", status.filename, status.err), a.window)
+		a.removeProgressRowLocked(status.jobID)
+		dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("error_processing_file", status.filename), status.err), a.window)
+		return
+	}
+
+	switch status.stage {
+	case "encoding", "transcribing":
+		a.setProgressRowLocked(status.jobID, status.filename, status.stage, status.fraction)
 		return
 	}
 
-	statusText := fmt.Sprintf("File: %s\nStatus: %s", status.filename, status.stage)
+	statusText := i18n.T("status_line", status.filename, localizedStage(status.stage))
 	a.components.statusLabel.SetText(statusText)
 	a.components.statusLabel.Refresh()
 
 	if status.stage == "completed" {
-		resultLabel := widget.NewLabel(fmt.Sprintf("Completed: %s", status.filename))
-		a.components.resultsList.Add(resultLabel)
+		a.removeProgressRowLocked(status.jobID)
+
+		a.components.resultsList.Add(a.newResultRow(status.filename, status.output))
 		a.components.resultsList.Refresh()
 		a.components.resultsList.Show() // Ensure the results list is visible
 	}
 }
 
+// newResultRow builds the UI for one finished job: a label plus actions to
+// copy, save, or open its transcription. output is nil if the job completed
+// without its text available (e.g. a stale event replayed from a restart),
+// in which case the actions are omitted.
+func (a *App) newResultRow(filename string, output *scriber.Output) *fyne.Container {
+	label := widget.NewLabel(i18n.T("completed_label", filename))
+	if output == nil {
+		return container.NewHBox(label)
+	}
+
+	copyBtn := widget.NewButtonWithIcon(i18n.T("action_copy"), theme.ContentCopyIcon(), func() {
+		a.window.Clipboard().SetContent(string(output.Text))
+	})
+
+	saveBtn := widget.NewButtonWithIcon(i18n.T("action_save_as"), theme.DocumentSaveIcon(), func() {
+		fd := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+			if _, err := writer.Write(output.Text); err != nil {
+				dialog.ShowError(err, a.window)
+			}
+		}, a.window)
+		fd.SetFileName(output.Name)
+		fd.Show()
+	})
+
+	openBtn := widget.NewButtonWithIcon(i18n.T("action_open"), theme.DocumentIcon(), func() {
+		a.openResult(output)
+	})
+
+	return container.NewHBox(label, copyBtn, saveBtn, openBtn)
+}
+
+// openResult writes output to a temp file and hands it to the OS's default
+// handler for its extension.
+func (a *App) openResult(output *scriber.Output) {
+	path := filepath.Join(os.TempDir(), output.Name)
+	if err := os.WriteFile(path, output.Text, 0o644); err != nil {
+		dialog.ShowError(fmt.Errorf("%s: %w", i18n.T("error_writing_file"), err), a.window)
+		return
+	}
+	u, err := url.Parse(storage.NewFileURI(path).String())
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	if err := a.fyneApp.OpenURL(u); err != nil {
+		dialog.ShowError(err, a.window)
+	}
+}
+
+// localizedStage translates one of the internal sentinel stage strings
+// ("processing", "encoding", "transcribing", "completed", "failed") into
+// the active locale for display. The sentinels themselves stay in English
+// since they're also compared via == elsewhere.
+func localizedStage(stage string) string {
+	switch stage {
+	case "processing":
+		return i18n.T("stage_processing")
+	case "encoding":
+		return i18n.T("stage_encoding")
+	case "transcribing":
+		return i18n.T("stage_transcribing")
+	case "completed":
+		return i18n.T("stage_completed")
+	case "failed":
+		return i18n.T("stage_failed")
+	default:
+		return stage
+	}
+}
+
+// setProgressRowLocked creates or updates the progress row for jobID.
+// Callers must hold a.mu.
+func (a *App) setProgressRowLocked(jobID, filename, stage string, fraction float64) {
+	row, ok := a.progressRows[jobID]
+	if !ok {
+		row = &progressRow{
+			bar:   widget.NewProgressBar(),
+			label: widget.NewLabel(""),
+		}
+		row.box = container.NewVBox(row.label, row.bar)
+		a.progressRows[jobID] = row
+		a.components.progressRows.Add(row.box)
+		a.components.progressRows.Show()
+	}
+
+	row.label.SetText(fmt.Sprintf("%s: %s", filename, localizedStage(stage)))
+	row.bar.SetValue(fraction)
+}
+
+// removeProgressRowLocked drops jobID's progress row, if any. Callers must
+// hold a.mu.
+func (a *App) removeProgressRowLocked(jobID string) {
+	row, ok := a.progressRows[jobID]
+	if !ok {
+		return
+	}
+	delete(a.progressRows, jobID)
+
+	a.components.progressRows.Remove(row.box)
+	if len(a.progressRows) == 0 {
+		a.components.progressRows.Hide()
+	}
+}
+
 func (a *App) setupProcessing() {
 	a.components.processBtn.OnTapped = func() {
-		a.components.progressBar.Show()
 		a.components.statusLabel.Show()
 		go a.processFiles()
 	}
@@ -359,22 +811,66 @@ func (a *App) setupProcessing() {
 }
 
 func (a *App) processFiles() {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
 	for _, file := range a.selectedFiles {
-		a.components.statusCh <- progressStatus{filename: file.name, stage: "processing"}
-
-		if err := a.scriberSvc.Process(a.ctx, &scriberInput{
-			name:       file.name,
-			language:   file.language,
-			outputType: file.outputType,
-			data:       file.data,
-		}); err != nil {
+		jobID, err := a.scriberSvc.Enqueue(a.ctx, &scriberInput{
+			name:        file.name,
+			language:    file.language,
+			outputType:  file.outputType,
+			model:       file.model,
+			prompt:      file.prompt,
+			temperature: file.temperature,
+			translate:   file.translate,
+			data:        file.data,
+		})
+		if err != nil {
 			a.components.statusCh <- progressStatus{filename: file.name, stage: "failed", err: err}
 			continue
 		}
-		a.components.statusCh <- progressStatus{filename: file.name, stage: "completed"}
+		a.jobFiles[jobID] = file.name
+	}
+}
+
+// handleEvents translates scriber.Event into the progressStatus messages
+// the UI already knows how to render. It subscribes its own event channel
+// rather than sharing one with other consumers (transcriboctl, via the
+// daemon), since a scriber.Queue fans out to every subscriber individually.
+func (a *App) handleEvents() {
+	for event := range a.scriberSvc.Subscribe() {
+		a.mu.RLock()
+		filename, ok := a.jobFiles[event.JobID]
+		sourcePath, watched := a.watchJobPaths[event.JobID]
+		a.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		switch event.Type {
+		case scriber.EventStarted, scriber.EventRetrying:
+			a.components.statusCh <- progressStatus{jobID: event.JobID, filename: filename, stage: "processing"}
+		case scriber.EventProgress:
+			a.components.statusCh <- progressStatus{
+				jobID:    event.JobID,
+				filename: filename,
+				stage:    event.Stage,
+				fraction: event.Fraction,
+			}
+		case scriber.EventFailed:
+			a.components.statusCh <- progressStatus{jobID: event.JobID, filename: filename, stage: "failed", err: event.Err}
+		case scriber.EventCompleted:
+			if watched && event.Output != nil {
+				a.writeWatchedResult(sourcePath, event.Output)
+			}
+			a.components.statusCh <- progressStatus{jobID: event.JobID, filename: filename, stage: "completed", output: event.Output}
+		}
+
+		if event.Type == scriber.EventFailed || event.Type == scriber.EventCompleted {
+			a.mu.Lock()
+			delete(a.jobFiles, event.JobID)
+			delete(a.watchJobPaths, event.JobID)
+			a.mu.Unlock()
+		}
 	}
-	a.components.progressBar.Hide()
 }
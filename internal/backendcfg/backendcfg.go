@@ -0,0 +1,106 @@
+// Package backendcfg stores and resolves which scriber.Transcriber backend
+// to use. It's shared by main.go, transcriboctl, and the GUI's settings
+// dialog in internal/app, so the headless CLI doesn't need to depend on
+// the Fyne-based Preferences that internal/app uses for GUI-only settings.
+package backendcfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/alesr/transcribo/internal/scriber"
+)
+
+// Backend names selectable in Config.Backend.
+const (
+	OpenAI       = "openai"
+	WhisperCPP   = "whispercpp"
+	OpenAICompat = "openai_compat"
+)
+
+// Names lists the selectable backends, in the order a picker should offer
+// them.
+var Names = []string{OpenAI, WhisperCPP, OpenAICompat}
+
+// Config is the persisted backend choice and its per-backend settings.
+type Config struct {
+	Backend             string `json:"backend"`
+	WhisperCPPBinPath   string `json:"whispercpp_bin_path"`
+	WhisperCPPModelPath string `json:"whispercpp_model_path"`
+	OpenAICompatBaseURL string `json:"openai_compat_base_url"`
+	OpenAICompatAPIKey  string `json:"openai_compat_api_key"`
+}
+
+// Path returns the default location Config is stored at.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving config dir: %w", err)
+	}
+	return filepath.Join(dir, "transcribo", "backend.json"), nil
+}
+
+// Load reads Config from path. A not-yet-created file returns the zero
+// Config, which NewTranscriber treats as the openai backend, rather than an
+// error.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to path, creating its parent directory if needed.
+func Save(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling backend config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// NewTranscriber builds the scriber.Transcriber cfg selects. openAIKey and
+// defaultModel back the openai backend, the only one requiring an API key;
+// httpClient is reused for both the openai and openai_compat backends.
+func NewTranscriber(cfg Config, openAIKey, defaultModel string, httpClient *http.Client) (scriber.Transcriber, error) {
+	switch cfg.Backend {
+	case WhisperCPP:
+		bin := cfg.WhisperCPPBinPath
+		if bin == "" {
+			bin = "whisper-cli"
+		}
+		if cfg.WhisperCPPModelPath == "" {
+			return nil, fmt.Errorf("whisper.cpp backend selected but no model path configured")
+		}
+		return scriber.NewWhisperCPP(bin, cfg.WhisperCPPModelPath), nil
+
+	case OpenAICompat:
+		if cfg.OpenAICompatBaseURL == "" {
+			return nil, fmt.Errorf("openai-compatible backend selected but no base URL configured")
+		}
+		return scriber.NewOpenAICompat(cfg.OpenAICompatBaseURL, cfg.OpenAICompatAPIKey, httpClient), nil
+
+	default:
+		if openAIKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required")
+		}
+		return scriber.NewOpenAIWhisper(openAIKey, defaultModel, httpClient), nil
+	}
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/alesr/transcribo/internal/scriber"
+)
+
+// Client talks to a running Server over its Unix socket.
+type Client struct {
+	socketPath string
+}
+
+// Dial connects to the daemon listening on socketPath, returning an error
+// if nothing is listening there.
+func Dial(socketPath string) (*Client, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", socketPath, err)
+	}
+	conn.Close()
+	return &Client{socketPath: socketPath}, nil
+}
+
+// Enqueue asks the daemon to transcribe the file at path, returning its job
+// id.
+func (c *Client) Enqueue(path, name, language, outputType string, priority int) (string, error) {
+	var result enqueueResult
+	if err := c.call(methodEnqueue, EnqueueParams{
+		Path:       path,
+		Name:       name,
+		Language:   language,
+		OutputType: outputType,
+		Priority:   priority,
+	}, &result); err != nil {
+		return "", err
+	}
+	return result.JobID, nil
+}
+
+// Cancel asks the daemon to cancel jobID.
+func (c *Client) Cancel(jobID string) error {
+	return c.call(methodCancel, cancelParams{JobID: jobID}, nil)
+}
+
+func (c *Client) call(method string, params, result any) error {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", c.socketPath, err)
+	}
+	defer conn.Close()
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(request{Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := conn.Write(line); err != nil {
+		return fmt.Errorf("writing request: %w", err)
+	}
+
+	respLine, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(respLine, &resp); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if result != nil && resp.Result != nil {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Watch opens a dedicated connection and streams scriber.Event values from
+// the daemon until the connection is closed or the daemon stops.
+func (c *Client) Watch() (<-chan scriber.Event, error) {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", c.socketPath, err)
+	}
+
+	line, err := json.Marshal(request{Method: methodWatch})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	line = append(line, '\n')
+	if _, err := conn.Write(line); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("writing request: %w", err)
+	}
+
+	events := make(chan scriber.Event, 32)
+	go func() {
+		defer close(events)
+		defer conn.Close()
+
+		dec := json.NewDecoder(conn)
+		for {
+			var w wireEvent
+			if err := dec.Decode(&w); err != nil {
+				return
+			}
+			events <- fromWireEvent(w)
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,268 @@
+// Package daemon exposes a scriber.Queue over a local Unix socket, so the
+// Fyne app and the transcriboctl CLI can share one running set of workers
+// instead of each spawning its own ffmpeg/Whisper processes.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alesr/transcribo/internal/scriber"
+)
+
+// SocketPath returns the default Unix socket path for the daemon.
+func SocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "transcribo.sock")
+	}
+	return filepath.Join(os.TempDir(), "transcribo.sock")
+}
+
+// method names understood by Server.
+const (
+	methodEnqueue = "enqueue"
+	methodCancel  = "cancel"
+	methodWatch   = "watch"
+)
+
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// EnqueueParams describes a file to transcribe, identified by path so it
+// can be reopened on the daemon side regardless of which process sent it.
+type EnqueueParams struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Language   string `json:"language"`
+	OutputType string `json:"output_type"`
+	Priority   int    `json:"priority"`
+}
+
+type enqueueResult struct {
+	JobID string `json:"job_id"`
+}
+
+type cancelParams struct {
+	JobID string `json:"job_id"`
+}
+
+// queue is the subset of *scriber.Queue the daemon depends on.
+type queue interface {
+	EnqueueWithPriority(ctx context.Context, in scriber.Input, priority int) (string, error)
+	Cancel(jobID string) error
+	Subscribe() <-chan scriber.Event
+}
+
+// Server serves daemon requests over a Unix socket on behalf of a single
+// shared scriber.Queue.
+type Server struct {
+	logger   *slog.Logger
+	queue    queue
+	listener net.Listener
+
+	mu       sync.Mutex
+	watchers map[chan scriber.Event]struct{}
+}
+
+// NewServer builds a Server around queue. Call ListenAndServe to start
+// accepting connections.
+func NewServer(logger *slog.Logger, q queue) *Server {
+	return &Server{
+		logger:   logger.WithGroup("daemon"),
+		queue:    q,
+		watchers: make(map[chan scriber.Event]struct{}),
+	}
+}
+
+// ListenAndServe listens on socketPath and blocks serving connections until
+// Close is called or accept fails.
+func (s *Server) ListenAndServe(socketPath string) error {
+	_ = os.Remove(socketPath)
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", socketPath, err)
+	}
+	s.listener = ln
+
+	go s.fanOutEvents()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Close stops the listener. Safe to call even if ListenAndServe was never
+// called.
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) fanOutEvents() {
+	for event := range s.queue.Subscribe() {
+		s.mu.Lock()
+		for ch := range s.watchers {
+			select {
+			case ch <- event:
+			default:
+				s.logger.Warn("dropping event for slow watcher")
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		writeResponse(conn, response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	switch req.Method {
+	case methodEnqueue:
+		s.handleEnqueue(conn, req.Params)
+	case methodCancel:
+		s.handleCancel(conn, req.Params)
+	case methodWatch:
+		s.handleWatch(conn)
+	default:
+		writeResponse(conn, response{Error: fmt.Sprintf("unknown method %q", req.Method)})
+	}
+}
+
+func (s *Server) handleEnqueue(conn net.Conn, raw json.RawMessage) {
+	var params EnqueueParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		writeResponse(conn, response{Error: err.Error()})
+		return
+	}
+
+	in := scriber.NewFileInput(params.Path, params.Name, params.Language, params.OutputType)
+	jobID, err := s.queue.EnqueueWithPriority(context.Background(), in, params.Priority)
+	if err != nil {
+		writeResponse(conn, response{Error: err.Error()})
+		return
+	}
+
+	result, _ := json.Marshal(enqueueResult{JobID: jobID})
+	writeResponse(conn, response{Result: result})
+}
+
+func (s *Server) handleCancel(conn net.Conn, raw json.RawMessage) {
+	var params cancelParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		writeResponse(conn, response{Error: err.Error()})
+		return
+	}
+
+	if err := s.queue.Cancel(params.JobID); err != nil {
+		writeResponse(conn, response{Error: err.Error()})
+		return
+	}
+	writeResponse(conn, response{})
+}
+
+// wireEvent is scriber.Event's JSON wire form: Err doesn't survive
+// encoding/json as an error interface, so it's carried as a string.
+type wireEvent struct {
+	JobID    string            `json:"job_id"`
+	Type     scriber.EventType `json:"type"`
+	Attempt  int               `json:"attempt"`
+	Stage    string            `json:"stage,omitempty"`
+	Fraction float64           `json:"fraction,omitempty"`
+	Err      string            `json:"error,omitempty"`
+	Output   *scriber.Output   `json:"output,omitempty"`
+}
+
+func toWireEvent(e scriber.Event) wireEvent {
+	w := wireEvent{
+		JobID:    e.JobID,
+		Type:     e.Type,
+		Attempt:  e.Attempt,
+		Stage:    e.Stage,
+		Fraction: e.Fraction,
+		Output:   e.Output,
+	}
+	if e.Err != nil {
+		w.Err = e.Err.Error()
+	}
+	return w
+}
+
+func fromWireEvent(w wireEvent) scriber.Event {
+	e := scriber.Event{
+		JobID:    w.JobID,
+		Type:     w.Type,
+		Attempt:  w.Attempt,
+		Stage:    w.Stage,
+		Fraction: w.Fraction,
+		Output:   w.Output,
+	}
+	if w.Err != "" {
+		e.Err = errors.New(w.Err)
+	}
+	return e
+}
+
+// handleWatch dedicates the connection to streaming scriber.Event values,
+// one JSON object per line, until the client disconnects.
+func (s *Server) handleWatch(conn net.Conn) {
+	ch := make(chan scriber.Event, 32)
+
+	s.mu.Lock()
+	s.watchers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, ch)
+		s.mu.Unlock()
+	}()
+
+	enc := json.NewEncoder(conn)
+	for event := range ch {
+		if err := enc.Encode(toWireEvent(event)); err != nil {
+			return
+		}
+	}
+}
+
+func writeResponse(conn net.Conn, resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+	_, _ = conn.Write(data)
+}
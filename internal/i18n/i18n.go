@@ -0,0 +1,93 @@
+// Package i18n translates the strings internal/app shows to the user. It
+// wraps github.com/nicksnyder/go-i18n/v2/i18n with embedded TOML message
+// catalogs, one per supported locale, and a package-level T helper so
+// callers don't have to thread a Localizer through every function.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	goi18n "github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.toml
+var localeFiles embed.FS
+
+// SupportedLanguages are the locales with a bundled message catalog, in the
+// order a language picker should list them.
+var SupportedLanguages = []string{"en", "es", "pt", "ru", "de"}
+
+var bundle = newBundle()
+
+func newBundle() *goi18n.Bundle {
+	b := goi18n.NewBundle(language.English)
+	b.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+
+	for _, lang := range SupportedLanguages {
+		if _, err := b.LoadMessageFileFS(localeFiles, "locales/active."+lang+".toml"); err != nil {
+			panic(fmt.Sprintf("i18n: loading %s message catalog: %v", lang, err))
+		}
+	}
+	return b
+}
+
+var (
+	mu        sync.RWMutex
+	localizer = goi18n.NewLocalizer(bundle, DetectLanguage())
+)
+
+// DetectLanguage picks a supported locale from the LANG environment
+// variable (e.g. "pt_BR.UTF-8" -> "pt"), falling back to English.
+func DetectLanguage() string {
+	return detectLanguage(os.Getenv("LANG"))
+}
+
+func detectLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	lang = strings.SplitN(lang, ".", 2)[0]
+	lang = strings.SplitN(lang, "_", 2)[0]
+
+	for _, supported := range SupportedLanguages {
+		if lang == supported {
+			return supported
+		}
+	}
+	return "en"
+}
+
+// SetLanguage switches the locale T translates into. Safe to call from any
+// goroutine.
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	localizer = goi18n.NewLocalizer(bundle, lang)
+}
+
+// T looks up id in the active locale's message catalog. If args are given,
+// the catalog entry is treated as an fmt.Sprintf template and formatted
+// with them; this keeps catalog entries readable "%s"-style strings
+// instead of go-i18n's usual Go-template TemplateData. It falls back to id
+// itself if the message is missing, so an untranslated string still shows
+// up as something readable rather than going blank.
+func T(id string, args ...any) string {
+	mu.RLock()
+	l := localizer
+	mu.RUnlock()
+
+	msg, err := l.Localize(&goi18n.LocalizeConfig{MessageID: id})
+	if err != nil {
+		slog.Default().Warn("missing translation", slog.String("id", id), slog.Any("error", err))
+		msg = id
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,394 @@
+package scriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// chunkWindow is the target length of each piece an over-threshold
+	// input is split into. At this length even a generously encoded clip
+	// stays comfortably under Whisper's 25 MB per-request limit.
+	chunkWindow = 10 * time.Minute
+
+	// silenceSearchWindow is how far transcribeChunked looks around each
+	// chunkWindow boundary for a quiet moment to cut on, so a cut doesn't
+	// land mid-word.
+	silenceSearchWindow = 15 * time.Second
+
+	// silenceAmplitude is the largest 16-bit PCM sample magnitude treated
+	// as silence when hunting for a cut point.
+	silenceAmplitude = 400
+
+	// maxParallelChunks bounds how many chunks are uploaded at once.
+	maxParallelChunks = 4
+)
+
+// pcmChunk is one piece of a split input: a standalone, playable WAV file
+// starting start into the original audio.
+type pcmChunk struct {
+	start time.Duration
+	wav   []byte
+}
+
+// transcribeChunked splits pcm (the WAV stream encodeToWAV produced) into
+// pieces around chunkWindow long, transcribes them concurrently, and
+// merges the results back into one Output, offsetting subtitle timestamps
+// by each chunk's start time so the merged transcript reads as one
+// continuous file.
+func transcribeChunked(ctx context.Context, transcriber Transcriber, in Input, pcm io.Reader, logger *slog.Logger, report reportFunc) (Output, error) {
+	chunks, err := splitPCMIntoChunks(pcm)
+	if err != nil {
+		return Output{}, fmt.Errorf("splitting into chunks: %w", err)
+	}
+
+	logger.Info("split input into chunks", slog.String("file", in.Name()), slog.Int("chunks", len(chunks)))
+
+	results := make([][]byte, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, maxParallelChunks)
+		completed int32
+	)
+	for i, c := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, c pcmChunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			text, err := transcriber.TranscribeAudio(ctx, TranscribeInput{
+				Name:        fmt.Sprintf("%s.part%d.wav", in.Name(), i),
+				Language:    in.Language(),
+				Format:      in.OutputType(),
+				Model:       in.Model(),
+				Prompt:      in.Prompt(),
+				Temperature: in.Temperature(),
+				Translate:   in.Translate(),
+				Data:        bytes.NewReader(c.wav),
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("chunk %d: %w", i, err)
+				return
+			}
+			results[i] = text
+
+			done := atomic.AddInt32(&completed, 1)
+			report("transcribing", encodingWeight+clampFraction(float64(done)/float64(len(chunks)))*transcribingWeight)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Output{}, fmt.Errorf("transcription failed: %w", err)
+		}
+	}
+
+	merged, err := mergeChunkResults(in.OutputType(), chunks, results)
+	if err != nil {
+		return Output{}, fmt.Errorf("merging chunk results: %w", err)
+	}
+
+	return Output{
+		Name: strings.Replace(in.Name(), filepath.Ext(in.Name()), "."+in.OutputType(), 1),
+		Text: merged,
+	}, nil
+}
+
+// wavHeader holds the fields of a canonical 44-byte RIFF/WAVE header that
+// splitPCMIntoChunks and buildWAV need to carry over to each chunk.
+type wavHeader struct {
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+}
+
+// readWAVHeader reads and validates the canonical 44-byte header ffmpeg's
+// "-f wav" writes at the start of its output.
+func readWAVHeader(r io.Reader) (wavHeader, error) {
+	buf := make([]byte, 44)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return wavHeader{}, fmt.Errorf("reading wav header: %w", err)
+	}
+	if string(buf[0:4]) != "RIFF" || string(buf[8:12]) != "WAVE" {
+		return wavHeader{}, errors.New("not a RIFF/WAVE stream")
+	}
+	return wavHeader{
+		channels:      int(binary.LittleEndian.Uint16(buf[22:24])),
+		sampleRate:    int(binary.LittleEndian.Uint32(buf[24:28])),
+		bitsPerSample: int(binary.LittleEndian.Uint16(buf[34:36])),
+	}, nil
+}
+
+// buildWAV wraps data, raw PCM samples matching hdr, in a standalone
+// canonical WAV header so it can be uploaded as its own file.
+func buildWAV(hdr wavHeader, data []byte) []byte {
+	byteRate := hdr.sampleRate * hdr.channels * hdr.bitsPerSample / 8
+	blockAlign := hdr.channels * hdr.bitsPerSample / 8
+
+	buf := make([]byte, 44+len(data))
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+len(data)))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], 1) // PCM
+	binary.LittleEndian.PutUint16(buf[22:24], uint16(hdr.channels))
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(hdr.sampleRate))
+	binary.LittleEndian.PutUint32(buf[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(buf[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(buf[34:36], uint16(hdr.bitsPerSample))
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(len(data)))
+	copy(buf[44:], data)
+	return buf
+}
+
+// splitPCMIntoChunks reads a RIFF/WAVE stream and cuts it into pieces
+// roughly chunkWindow long, nudging each cut to the nearest near-silent
+// frame within silenceSearchWindow so it doesn't land mid-word.
+func splitPCMIntoChunks(pcm io.Reader) ([]pcmChunk, error) {
+	hdr, err := readWAVHeader(pcm)
+	if err != nil {
+		return nil, err
+	}
+	if hdr.bitsPerSample != 16 {
+		return nil, fmt.Errorf("chunking only supports 16-bit PCM, got %d-bit", hdr.bitsPerSample)
+	}
+
+	frameSize := int64(hdr.channels) * int64(hdr.bitsPerSample/8)
+	bytesPerSec := frameSize * int64(hdr.sampleRate)
+	targetSize := alignToFrame(int64(chunkWindow.Seconds()*float64(bytesPerSec)), frameSize)
+	searchSize := alignToFrame(int64(silenceSearchWindow.Seconds()*float64(bytesPerSec)), frameSize)
+
+	var (
+		chunks  []pcmChunk
+		elapsed time.Duration
+		pending []byte
+		eof     bool
+		buf     = make([]byte, 1<<20)
+	)
+
+	for {
+		for !eof && int64(len(pending)) < targetSize+searchSize {
+			n, err := pcm.Read(buf)
+			pending = append(pending, buf[:n]...)
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					return nil, fmt.Errorf("reading pcm: %w", err)
+				}
+				eof = true
+			}
+		}
+
+		if len(pending) == 0 {
+			return chunks, nil
+		}
+
+		cut := int64(len(pending))
+		last := cut <= targetSize
+		if !last {
+			cut = nearestSilentFrame(pending, targetSize, frameSize, searchSize, hdr.channels)
+		}
+
+		data := pending[:cut]
+		chunks = append(chunks, pcmChunk{start: elapsed, wav: buildWAV(hdr, data)})
+		elapsed += bytesToDuration(int64(len(data)), bytesPerSec)
+		pending = pending[cut:]
+
+		if last {
+			return chunks, nil
+		}
+	}
+}
+
+// nearestSilentFrame looks outward from target (in both directions, up to
+// searchSize) for a frame where every channel is at or below
+// silenceAmplitude, returning the nearest one found. If none is found it
+// just returns target, rounded down to a frame boundary.
+func nearestSilentFrame(pcm []byte, target, frameSize, searchSize int64, channels int) int64 {
+	maxOffsetFrames := searchSize / frameSize
+	for offset := int64(0); offset <= maxOffsetFrames; offset++ {
+		for _, sign := range [2]int64{1, -1} {
+			pos := target + sign*offset*frameSize
+			if pos < 0 || pos+frameSize > int64(len(pcm)) {
+				continue
+			}
+			if isSilentFrame(pcm[pos:pos+frameSize], channels) {
+				return pos
+			}
+			if offset == 0 {
+				break // +0 and -0 are the same position
+			}
+		}
+	}
+	return alignToFrame(target, frameSize)
+}
+
+func isSilentFrame(frame []byte, channels int) bool {
+	for c := 0; c < channels; c++ {
+		sample := int(int16(binary.LittleEndian.Uint16(frame[c*2 : c*2+2])))
+		if sample < 0 {
+			sample = -sample
+		}
+		if sample > silenceAmplitude {
+			return false
+		}
+	}
+	return true
+}
+
+func alignToFrame(n, frameSize int64) int64 {
+	return n - (n % frameSize)
+}
+
+func bytesToDuration(n, bytesPerSec int64) time.Duration {
+	if bytesPerSec == 0 {
+		return 0
+	}
+	return time.Duration(float64(n) / float64(bytesPerSec) * float64(time.Second))
+}
+
+// mergeChunkResults stitches per-chunk transcription output back into a
+// single Output.Text, offsetting subtitle timestamps by each chunk's start
+// time for srt/vtt so the result reads as one continuous transcript. Other
+// formats (text, json, verbose_json) have no per-cue timestamps to shift,
+// so their chunks are just concatenated in order.
+func mergeChunkResults(format string, chunks []pcmChunk, results [][]byte) ([]byte, error) {
+	switch format {
+	case "srt":
+		return mergeSRT(chunks, results)
+	case "vtt":
+		return mergeVTT(chunks, results)
+	default:
+		var merged bytes.Buffer
+		for _, r := range results {
+			merged.Write(bytes.TrimSpace(r))
+			merged.WriteString("\n\n")
+		}
+		return bytes.TrimSpace(merged.Bytes()), nil
+	}
+}
+
+var (
+	srtTimeRe = regexp.MustCompile(`(\d{2}):(\d{2}):(\d{2}),(\d{3})\s*-->\s*(\d{2}):(\d{2}):(\d{2}),(\d{3})`)
+	vttTimeRe = regexp.MustCompile(`(\d{2}):(\d{2}):(\d{2})\.(\d{3})\s*-->\s*(\d{2}):(\d{2}):(\d{2})\.(\d{3})`)
+)
+
+func mergeSRT(chunks []pcmChunk, results [][]byte) ([]byte, error) {
+	var out bytes.Buffer
+	seq := 1
+	for i, block := range results {
+		for _, cue := range splitCueBlocks(block) {
+			lines := strings.Split(cue, "\n")
+			if len(lines) < 2 {
+				continue
+			}
+			shifted, err := shiftTimeLine(srtTimeRe, lines[1], chunks[i].start, formatSRTTimestamp)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&out, "%d\n%s\n%s\n\n", seq, shifted, strings.Join(lines[2:], "\n"))
+			seq++
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func mergeVTT(chunks []pcmChunk, results [][]byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("WEBVTT\n\n")
+	for i, block := range results {
+		for _, cue := range splitCueBlocks(stripVTTHeader(block)) {
+			lines := strings.Split(cue, "\n")
+			idx := 0
+			if !vttTimeRe.MatchString(lines[0]) {
+				idx = 1 // optional cue identifier line before the timing line
+			}
+			if idx >= len(lines) {
+				continue
+			}
+			shifted, err := shiftTimeLine(vttTimeRe, lines[idx], chunks[i].start, formatVTTTimestamp)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(&out, "%s\n%s\n\n", shifted, strings.Join(lines[idx+1:], "\n"))
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func splitCueBlocks(data []byte) []string {
+	normalized := strings.ReplaceAll(strings.TrimSpace(string(data)), "\r\n", "\n")
+	if normalized == "" {
+		return nil
+	}
+
+	var blocks []string
+	for _, b := range strings.Split(normalized, "\n\n") {
+		if strings.TrimSpace(b) != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func stripVTTHeader(data []byte) []byte {
+	s := strings.TrimSpace(string(data))
+	s = strings.TrimPrefix(s, "WEBVTT")
+	return []byte(strings.TrimSpace(s))
+}
+
+// shiftTimeLine re-renders a "start --> end" timestamp line, shifted by
+// offset, using re to parse it and format to render each side.
+func shiftTimeLine(re *regexp.Regexp, line string, offset time.Duration, format func(time.Duration) string) (string, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return "", fmt.Errorf("unrecognized timestamp line: %q", line)
+	}
+	start := parseTimestamp(m[1:5]) + offset
+	end := parseTimestamp(m[5:9]) + offset
+	return fmt.Sprintf("%s --> %s", format(start), format(end)), nil
+}
+
+func parseTimestamp(parts []string) time.Duration {
+	h, _ := strconv.Atoi(parts[0])
+	m, _ := strconv.Atoi(parts[1])
+	s, _ := strconv.Atoi(parts[2])
+	ms, _ := strconv.Atoi(parts[3])
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second + time.Duration(ms)*time.Millisecond
+}
+
+func formatSRTTimestamp(d time.Duration) string {
+	return formatTimestamp(d, ",")
+}
+
+func formatVTTTimestamp(d time.Duration) string {
+	return formatTimestamp(d, ".")
+}
+
+func formatTimestamp(d time.Duration, msSep string) string {
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	d -= s * time.Second
+	ms := d / time.Millisecond
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, msSep, ms)
+}
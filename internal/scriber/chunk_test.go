@@ -0,0 +1,107 @@
+package scriber
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAndFormatTimestamp(t *testing.T) {
+	d := parseTimestamp([]string{"01", "02", "03", "456"})
+	want := time.Hour + 2*time.Minute + 3*time.Second + 456*time.Millisecond
+	if d != want {
+		t.Fatalf("parseTimestamp = %v, want %v", d, want)
+	}
+
+	if got := formatSRTTimestamp(d); got != "01:02:03,456" {
+		t.Errorf("formatSRTTimestamp = %q", got)
+	}
+	if got := formatVTTTimestamp(d); got != "01:02:03.456" {
+		t.Errorf("formatVTTTimestamp = %q", got)
+	}
+}
+
+func TestShiftTimeLine(t *testing.T) {
+	got, err := shiftTimeLine(srtTimeRe, "00:00:01,000 --> 00:00:02,500", 10*time.Second, formatSRTTimestamp)
+	if err != nil {
+		t.Fatalf("shiftTimeLine returned error: %v", err)
+	}
+	want := "00:00:11,000 --> 00:00:12,500"
+	if got != want {
+		t.Errorf("shiftTimeLine = %q, want %q", got, want)
+	}
+
+	if _, err := shiftTimeLine(srtTimeRe, "not a timestamp", 0, formatSRTTimestamp); err == nil {
+		t.Error("expected an error for an unrecognized timestamp line")
+	}
+}
+
+func TestMergeSRTShiftsTimestampsByChunkStart(t *testing.T) {
+	chunks := []pcmChunk{
+		{start: 0},
+		{start: 10 * time.Minute},
+	}
+	results := [][]byte{
+		[]byte("1\n00:00:01,000 --> 00:00:02,000\nhello\n"),
+		[]byte("1\n00:00:00,500 --> 00:00:01,500\nworld\n"),
+	}
+
+	merged, err := mergeSRT(chunks, results)
+	if err != nil {
+		t.Fatalf("mergeSRT returned error: %v", err)
+	}
+
+	want := "1\n00:00:01,000 --> 00:00:02,000\nhello\n\n" +
+		"2\n00:10:00,500 --> 00:10:01,500\nworld\n\n"
+	if string(merged) != want {
+		t.Errorf("mergeSRT =\n%s\nwant\n%s", merged, want)
+	}
+}
+
+func TestMergeVTTShiftsTimestampsByChunkStart(t *testing.T) {
+	chunks := []pcmChunk{
+		{start: 0},
+		{start: time.Minute},
+	}
+	results := [][]byte{
+		[]byte("WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nhello\n"),
+		[]byte("WEBVTT\n\ncue-1\n00:00:00.500 --> 00:00:01.500\nworld\n"),
+	}
+
+	merged, err := mergeVTT(chunks, results)
+	if err != nil {
+		t.Fatalf("mergeVTT returned error: %v", err)
+	}
+
+	want := "WEBVTT\n\n" +
+		"00:00:01.000 --> 00:00:02.000\nhello\n\n" +
+		"00:01:00.500 --> 00:01:01.500\nworld\n\n"
+	if string(merged) != want {
+		t.Errorf("mergeVTT =\n%s\nwant\n%s", merged, want)
+	}
+}
+
+func TestMergeChunkResultsPlainTextConcatenates(t *testing.T) {
+	chunks := []pcmChunk{{start: 0}, {start: time.Minute}}
+	results := [][]byte{[]byte(" hello "), []byte(" world ")}
+
+	merged, err := mergeChunkResults("text", chunks, results)
+	if err != nil {
+		t.Fatalf("mergeChunkResults returned error: %v", err)
+	}
+	if string(merged) != "hello\n\nworld" {
+		t.Errorf("mergeChunkResults(text) = %q", merged)
+	}
+}
+
+func TestSplitCueBlocks(t *testing.T) {
+	blocks := splitCueBlocks([]byte("a\nb\n\nc\n\nd"))
+	want := []string{"a\nb", "c", "d"}
+	if len(blocks) != len(want) {
+		t.Fatalf("splitCueBlocks returned %d blocks, want %d", len(blocks), len(want))
+	}
+	for i := range want {
+		if blocks[i] != want[i] {
+			t.Errorf("block %d = %q, want %q", i, blocks[i], want[i])
+		}
+	}
+}
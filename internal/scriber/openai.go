@@ -0,0 +1,32 @@
+package scriber
+
+import (
+	"context"
+	"net/http"
+)
+
+// OpenAIWhisper talks to the hosted OpenAI Whisper API. OpenAI's own
+// transcription endpoint is the origin of the "OpenAI-compatible" format,
+// so this is just OpenAICompat pointed at OpenAI's base URL, with
+// defaultModel filled in for callers (such as FileInput-backed jobs) that
+// don't set a per-file Model override.
+type OpenAIWhisper struct {
+	*OpenAICompat
+	defaultModel string
+}
+
+// NewOpenAIWhisper returns a Transcriber backed by the OpenAI API. If
+// httpClient is nil, http.DefaultClient is used.
+func NewOpenAIWhisper(apiKey, defaultModel string, httpClient *http.Client) *OpenAIWhisper {
+	return &OpenAIWhisper{
+		OpenAICompat: NewOpenAICompat("https://api.openai.com", apiKey, httpClient),
+		defaultModel: defaultModel,
+	}
+}
+
+func (w *OpenAIWhisper) TranscribeAudio(ctx context.Context, in TranscribeInput) ([]byte, error) {
+	if in.Model == "" {
+		in.Model = w.defaultModel
+	}
+	return w.OpenAICompat.TranscribeAudio(ctx, in)
+}
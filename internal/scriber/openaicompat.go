@@ -0,0 +1,117 @@
+package scriber
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// OpenAICompat talks to any self-hosted server implementing the OpenAI
+// /v1/audio/transcriptions API, such as a faster-whisper server. Unlike
+// OpenAIWhisper it isn't tied to api.openai.com, so BaseURL is mandatory;
+// APIKey may be empty for servers that don't require one.
+type OpenAICompat struct {
+	BaseURL string
+	APIKey  string
+	HTTP    *http.Client
+}
+
+// NewOpenAICompat returns a Transcriber backed by baseURL. If httpClient is
+// nil, http.DefaultClient is used.
+func NewOpenAICompat(baseURL, apiKey string, httpClient *http.Client) *OpenAICompat {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &OpenAICompat{BaseURL: baseURL, APIKey: apiKey, HTTP: httpClient}
+}
+
+// TranscribeAudio streams in.Data into a multipart request body through an
+// io.Pipe instead of building it in memory first: for a file the size this
+// tool targets (up to chunkThreshold before splitting), fully buffering the
+// multipart body would mean holding several times the audio's size in RAM
+// per in-flight request.
+func (o *OpenAICompat) TranscribeAudio(ctx context.Context, in TranscribeInput) ([]byte, error) {
+	endpoint := "/v1/audio/transcriptions"
+	if in.Translate {
+		endpoint = "/v1/audio/translations"
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	buildErrCh := make(chan error, 1)
+	go func() {
+		err := writeMultipartBody(mw, in)
+		buildErrCh <- err
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.BaseURL+endpoint, pr)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.HTTP.Do(req)
+	if err != nil {
+		if buildErr := <-buildErrCh; buildErr != nil {
+			return nil, fmt.Errorf("building request: %w", buildErr)
+		}
+		return nil, fmt.Errorf("calling %s: %w", o.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if buildErr := <-buildErrCh; buildErr != nil {
+		return nil, fmt.Errorf("building request: %w", buildErr)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", o.BaseURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s: %s", o.BaseURL, resp.Status, respBody)
+	}
+	return respBody, nil
+}
+
+// writeMultipartBody writes in as a multipart form into mw, closing mw (and
+// thereby writing its trailing boundary) once done. It runs on its own
+// goroutine, piped straight into the HTTP request body, so the caller must
+// not touch mw concurrently.
+func writeMultipartBody(mw *multipart.Writer, in TranscribeInput) error {
+	part, err := mw.CreateFormFile("file", in.Name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, in.Data); err != nil {
+		return err
+	}
+
+	fields := map[string]string{
+		"model":           in.Model,
+		"language":        in.Language,
+		"response_format": in.Format,
+		"prompt":          in.Prompt,
+	}
+	for field, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := mw.WriteField(field, value); err != nil {
+			return err
+		}
+	}
+	if in.Temperature != 0 {
+		if err := mw.WriteField("temperature", strconv.FormatFloat(in.Temperature, 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
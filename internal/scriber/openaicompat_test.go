@@ -0,0 +1,89 @@
+package scriber
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAICompatTranscribeAudioStreamsMultipartBody(t *testing.T) {
+	var (
+		gotFile   string
+		gotFields = map[string]string{}
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+			t.Fatalf("unexpected content type: %s (%v)", r.Header.Get("Content-Type"), err)
+		}
+
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("reading multipart part: %v", err)
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				t.Fatalf("reading part body: %v", err)
+			}
+			if part.FormName() == "file" {
+				gotFile = string(data)
+			} else {
+				gotFields[part.FormName()] = string(data)
+			}
+		}
+
+		w.Write([]byte(`1\n00:00:00,000 --> 00:00:01,000\nhello\n`))
+	}))
+	defer srv.Close()
+
+	o := NewOpenAICompat(srv.URL, "test-key", nil)
+	_, err := o.TranscribeAudio(context.Background(), TranscribeInput{
+		Name:     "clip.wav",
+		Language: "en",
+		Format:   "srt",
+		Model:    "whisper-1",
+		Data:     strings.NewReader("fake wav bytes"),
+	})
+	if err != nil {
+		t.Fatalf("TranscribeAudio returned error: %v", err)
+	}
+
+	if gotFile != "fake wav bytes" {
+		t.Errorf("server received file content %q, want %q", gotFile, "fake wav bytes")
+	}
+	if gotFields["model"] != "whisper-1" || gotFields["language"] != "en" || gotFields["response_format"] != "srt" {
+		t.Errorf("server received fields %+v", gotFields)
+	}
+}
+
+func TestOpenAICompatTranscribeAudioReturnsServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+
+	o := NewOpenAICompat(srv.URL, "", nil)
+	_, err := o.TranscribeAudio(context.Background(), TranscribeInput{
+		Name: "clip.wav",
+		Data: strings.NewReader("fake wav bytes"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected an error containing the server's response body, got %v", err)
+	}
+}
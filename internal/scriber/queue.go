@@ -0,0 +1,529 @@
+package scriber
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job priorities. Callers that don't care use PriorityNormal via Enqueue;
+// EnqueueWithPriority accepts any int, higher runs first.
+const (
+	PriorityLow    = 0
+	PriorityNormal = 5
+	PriorityHigh   = 10
+)
+
+const (
+	baseRetryBackoff = 2 * time.Second
+	maxRetryBackoff  = 8 * time.Second
+)
+
+// ErrJobNotFound is returned by Cancel when the job id is unknown.
+var ErrJobNotFound = errors.New("job not found")
+
+// EventType identifies the kind of Event emitted by a Queue.
+type EventType string
+
+const (
+	EventQueued    EventType = "queued"
+	EventStarted   EventType = "started"
+	EventProgress  EventType = "progress"
+	EventFailed    EventType = "failed"
+	EventRetrying  EventType = "retrying"
+	EventCompleted EventType = "completed"
+)
+
+// Event reports a state change for a queued job. Stage and Fraction are
+// only set for EventProgress; Output is only set for EventCompleted.
+type Event struct {
+	JobID    string
+	Type     EventType
+	Attempt  int
+	Stage    string
+	Fraction float64
+	Err      error
+	Output   *Output
+}
+
+type job struct {
+	id         string
+	in         Input
+	priority   int
+	seq        int64
+	attempt    int
+	maxRetries int
+	index      int
+	inHeap     bool // true iff the job is currently sitting in q.pq, waiting for a worker
+	canceled   bool // true once Cancel has removed the job; blocks a pending retry from requeuing it
+	cancel     context.CancelFunc
+}
+
+// jobHeap is a priority queue ordered by highest priority first, ties
+// broken by enqueue order (FIFO).
+type jobHeap []*job
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *jobHeap) Push(x any) {
+	j := x.(*job)
+	j.index = len(*h)
+	j.inHeap = true
+	*h = append(*h, j)
+}
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	j := old[n-1]
+	old[n-1] = nil
+	j.index = -1
+	j.inHeap = false
+	*h = old[:n-1]
+	return j
+}
+
+// Queue is a persistent, concurrent transcription pipeline: jobs are
+// submitted via Enqueue, picked up by a fixed pool of workers in priority
+// order, retried with exponential backoff on failure, and reported back as
+// a stream of Events to every subscriber.
+type Queue struct {
+	logger      *slog.Logger
+	transcriber Transcriber
+	workers     int
+	maxRetries  int
+	statePath   string
+
+	mu     sync.Mutex
+	pq     jobHeap
+	jobs   map[string]*job
+	notify chan struct{}
+	nextID int64
+
+	// nextSeq numbers jobs in the order they enter the queue, for FIFO
+	// tie-breaking in jobHeap. Guarded by mu.
+	nextSeq int64
+
+	subscribers map[chan Event]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewQueue creates a Queue backed by transcriber. workers controls how many
+// jobs run concurrently, maxRetries how many times a failed job is retried
+// before it's reported as EventFailed. statePath, if non-empty, is where
+// in-flight job metadata is persisted so jobs still queued or running at
+// shutdown can be resumed on the next Start.
+func NewQueue(logger *slog.Logger, transcriber Transcriber, workers, maxRetries int, statePath string) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		logger:      logger.WithGroup("scriber_queue"),
+		transcriber: transcriber,
+		workers:     workers,
+		maxRetries:  maxRetries,
+		statePath:   statePath,
+		jobs:        make(map[string]*job),
+		notify:      make(chan struct{}, 1),
+		subscribers: make(map[chan Event]struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start loads any persisted jobs and launches the worker pool. It must be
+// called once before Enqueue.
+func (q *Queue) Start() {
+	q.loadState()
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+}
+
+// Stop cancels all running jobs and waits for workers to exit.
+func (q *Queue) Stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue submits in for transcription at normal priority and returns its
+// job id.
+func (q *Queue) Enqueue(ctx context.Context, in Input) (string, error) {
+	return q.EnqueueWithPriority(ctx, in, PriorityNormal)
+}
+
+// EnqueueWithPriority is like Enqueue but lets the caller rank the job
+// against others already queued; higher values run first.
+func (q *Queue) EnqueueWithPriority(ctx context.Context, in Input, priority int) (string, error) {
+	j := &job{
+		id:         fmt.Sprintf("job-%d", atomic.AddInt64(&q.nextID, 1)),
+		in:         in,
+		priority:   priority,
+		maxRetries: q.maxRetries,
+	}
+
+	q.mu.Lock()
+	j.seq = q.nextSeq
+	q.nextSeq++
+	q.jobs[j.id] = j
+	heap.Push(&q.pq, j)
+	q.persistStateLocked()
+	q.mu.Unlock()
+
+	q.emit(Event{JobID: j.id, Type: EventQueued})
+	q.wake()
+
+	return j.id, nil
+}
+
+// Cancel stops job id: removes it from the queue if it's waiting (whether
+// for a free worker or a retry backoff), or cancels its context if it's
+// actively running. Either way, a terminal EventFailed follows on every
+// subscriber.
+func (q *Queue) Cancel(jobID string) error {
+	q.mu.Lock()
+	j, ok := q.jobs[jobID]
+	if !ok {
+		q.mu.Unlock()
+		return fmt.Errorf("job %s: %w", jobID, ErrJobNotFound)
+	}
+
+	if j.cancel != nil {
+		j.cancel()
+		q.mu.Unlock()
+		q.emit(Event{JobID: jobID, Type: EventFailed, Err: context.Canceled})
+		return nil
+	}
+
+	if j.inHeap {
+		heap.Remove(&q.pq, j.index)
+	}
+	j.canceled = true
+	delete(q.jobs, jobID)
+	q.persistStateLocked()
+	q.mu.Unlock()
+
+	q.emit(Event{JobID: jobID, Type: EventFailed, Err: context.Canceled})
+	return nil
+}
+
+// Subscribe registers a new listener for job lifecycle events and returns a
+// channel that receives every event emitted from this point on. It's never
+// closed by the Queue; callers should range over it alongside a
+// cancellation signal and call Unsubscribe when done, or the channel and
+// its slot in the subscriber set leak.
+func (q *Queue) Subscribe() <-chan Event {
+	ch := make(chan Event, 32)
+	q.mu.Lock()
+	q.subscribers[ch] = struct{}{}
+	q.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe removes a channel returned by Subscribe so it stops
+// receiving events.
+func (q *Queue) Unsubscribe(ch <-chan Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for c := range q.subscribers {
+		if c == ch {
+			delete(q.subscribers, c)
+			return
+		}
+	}
+}
+
+func (q *Queue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// emit fans e out to every current subscriber. A subscriber whose channel
+// is full has its event dropped rather than blocking the queue, the same
+// tradeoff daemon.Server.fanOutEvents makes for its watchers.
+func (q *Queue) emit(e Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for ch := range q.subscribers {
+		select {
+		case ch <- e:
+		default:
+			q.logger.Warn("dropping event for slow subscriber", slog.String("job_id", e.JobID))
+		}
+	}
+}
+
+func (q *Queue) next() (*job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.pq.Len() == 0 {
+		return nil, false
+	}
+	return heap.Pop(&q.pq).(*job), true
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		j, ok := q.next()
+		if !ok {
+			select {
+			case <-q.notify:
+				continue
+			case <-q.ctx.Done():
+				return
+			}
+		}
+		q.run(j)
+	}
+}
+
+func (q *Queue) run(j *job) {
+	jobCtx, cancel := context.WithCancel(q.ctx)
+
+	q.mu.Lock()
+	j.cancel = cancel
+	q.mu.Unlock()
+	defer cancel()
+
+	q.emit(Event{JobID: j.id, Type: EventStarted, Attempt: j.attempt})
+
+	out, err := transcribe(jobCtx, q.logger, q.transcriber, j.in, func(stage string, fraction float64) {
+		q.emit(Event{JobID: j.id, Type: EventProgress, Attempt: j.attempt, Stage: stage, Fraction: fraction})
+	})
+	if err != nil {
+		if jobCtx.Err() != nil {
+			q.logger.Info("job canceled", slog.String("job_id", j.id))
+			q.removeJob(j.id)
+			return
+		}
+
+		if j.attempt >= j.maxRetries {
+			q.emit(Event{JobID: j.id, Type: EventFailed, Attempt: j.attempt, Err: err})
+			q.removeJob(j.id)
+			return
+		}
+
+		j.attempt++
+		q.emit(Event{JobID: j.id, Type: EventRetrying, Attempt: j.attempt, Err: err})
+
+		q.mu.Lock()
+		j.cancel = nil
+		q.persistStateLocked()
+		q.mu.Unlock()
+
+		select {
+		case <-time.After(retryBackoff(j.attempt)):
+		case <-q.ctx.Done():
+			return
+		}
+
+		q.mu.Lock()
+		canceled := j.canceled
+		if !canceled {
+			heap.Push(&q.pq, j)
+		}
+		q.mu.Unlock()
+		if !canceled {
+			q.wake()
+		}
+		return
+	}
+
+	q.emit(Event{JobID: j.id, Type: EventCompleted, Attempt: j.attempt, Output: &out})
+	q.removeJob(j.id)
+}
+
+func (q *Queue) removeJob(id string) {
+	q.mu.Lock()
+	delete(q.jobs, id)
+	q.persistStateLocked()
+	q.mu.Unlock()
+}
+
+func retryBackoff(attempt int) time.Duration {
+	d := baseRetryBackoff << (attempt - 1)
+	if d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return d
+}
+
+// persistedJob is the on-disk representation of a job still queued or
+// in-progress. Only jobs whose Input implements Persistable are written,
+// since Input has no general way to serialize its Data().
+type persistedJob struct {
+	ID         string `json:"id"`
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	Language   string `json:"language"`
+	OutputType string `json:"output_type"`
+	Priority   int    `json:"priority"`
+	Attempt    int    `json:"attempt"`
+	MaxRetries int    `json:"max_retries"`
+}
+
+// persistStateLocked writes the current job set to statePath. Callers must
+// hold q.mu.
+func (q *Queue) persistStateLocked() {
+	if q.statePath == "" {
+		return
+	}
+
+	var pending []persistedJob
+	for _, j := range q.jobs {
+		p, ok := j.in.(Persistable)
+		if !ok {
+			continue
+		}
+		pending = append(pending, persistedJob{
+			ID:         j.id,
+			Path:       p.Path(),
+			Name:       j.in.Name(),
+			Language:   j.in.Language(),
+			OutputType: j.in.OutputType(),
+			Priority:   j.priority,
+			Attempt:    j.attempt,
+			MaxRetries: j.maxRetries,
+		})
+	}
+
+	data, err := json.Marshal(pending)
+	if err != nil {
+		q.logger.Error("marshal queue state", slog.Any("error", err))
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(q.statePath), 0o755); err != nil {
+		q.logger.Error("create queue state dir", slog.String("path", q.statePath), slog.Any("error", err))
+		return
+	}
+	if err := os.WriteFile(q.statePath, data, 0o644); err != nil {
+		q.logger.Error("write queue state", slog.String("path", q.statePath), slog.Any("error", err))
+	}
+}
+
+// loadState re-enqueues jobs left pending by a previous process, reopening
+// their data from disk. It's called once from Start.
+func (q *Queue) loadState() {
+	if q.statePath == "" {
+		return
+	}
+
+	data, err := os.ReadFile(q.statePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			q.logger.Error("read queue state", slog.String("path", q.statePath), slog.Any("error", err))
+		}
+		return
+	}
+
+	var pending []persistedJob
+	if err := json.Unmarshal(data, &pending); err != nil {
+		q.logger.Error("unmarshal queue state", slog.Any("error", err))
+		return
+	}
+
+	for _, p := range pending {
+		in, err := reopenFileInput(p)
+		if err != nil {
+			q.logger.Warn("dropping unresumable job", slog.String("job_id", p.ID), slog.Any("error", err))
+			continue
+		}
+
+		j := &job{
+			id:         p.ID,
+			in:         in,
+			priority:   p.Priority,
+			attempt:    p.Attempt,
+			maxRetries: p.MaxRetries,
+		}
+
+		q.mu.Lock()
+		j.seq = q.nextSeq
+		q.nextSeq++
+		q.jobs[j.id] = j
+		heap.Push(&q.pq, j)
+		q.mu.Unlock()
+
+		q.emit(Event{JobID: j.id, Type: EventQueued})
+	}
+}
+
+// FileInput is an Input backed by a path on disk rather than an in-memory
+// buffer. It reopens the file on every Data() call, so it can be queued,
+// retried, and resumed after a restart without holding the file in memory.
+type FileInput struct {
+	path       string
+	name       string
+	language   string
+	outputType string
+}
+
+// NewFileInput builds a FileInput for path. name is the display name
+// (typically filepath.Base(path)); language and outputType are forwarded to
+// the transcription backend as-is.
+func NewFileInput(path, name, language, outputType string) *FileInput {
+	return &FileInput{path: path, name: name, language: language, outputType: outputType}
+}
+
+func (in *FileInput) Name() string       { return in.name }
+func (in *FileInput) Language() string   { return in.language }
+func (in *FileInput) OutputType() string { return in.outputType }
+func (in *FileInput) Path() string       { return in.path }
+
+// Model, Prompt, Temperature and Translate are unsupported for FileInput:
+// callers that need per-file overrides (currently just the app's upload
+// flow) use scriberInput instead.
+func (in *FileInput) Model() string        { return "" }
+func (in *FileInput) Prompt() string       { return "" }
+func (in *FileInput) Temperature() float64 { return 0 }
+func (in *FileInput) Translate() bool      { return false }
+
+func (in *FileInput) Data() io.ReadCloser {
+	f, err := os.Open(in.path)
+	if err != nil {
+		return io.NopCloser(&erroringReader{err: err})
+	}
+	return f
+}
+
+func reopenFileInput(p persistedJob) (Input, error) {
+	if _, err := os.Stat(p.Path); err != nil {
+		return nil, fmt.Errorf("source file gone: %w", err)
+	}
+	return NewFileInput(p.Path, p.Name, p.Language, p.OutputType), nil
+}
+
+// erroringReader surfaces a deferred open error through Read, so a failed
+// reopen fails the job instead of panicking.
+type erroringReader struct{ err error }
+
+func (r *erroringReader) Read([]byte) (int, error) { return 0, r.err }
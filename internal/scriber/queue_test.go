@@ -0,0 +1,219 @@
+package scriber
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJobHeapPushPopTracksInHeap(t *testing.T) {
+	var h jobHeap
+
+	j1 := &job{id: "a", priority: PriorityNormal, seq: 1}
+	j2 := &job{id: "b", priority: PriorityHigh, seq: 2}
+	heap.Push(&h, j1)
+	heap.Push(&h, j2)
+
+	if !j1.inHeap || !j2.inHeap {
+		t.Fatalf("jobs pushed onto heap should have inHeap=true, got j1=%v j2=%v", j1.inHeap, j2.inHeap)
+	}
+	if j1.index < 0 || j2.index < 0 {
+		t.Fatalf("jobs in heap should have a non-negative index, got j1=%d j2=%d", j1.index, j2.index)
+	}
+
+	// Higher priority should come out first.
+	first := heap.Pop(&h).(*job)
+	if first != j2 {
+		t.Fatalf("expected higher-priority job b first, got %s", first.id)
+	}
+	if first.inHeap {
+		t.Fatal("popped job should have inHeap=false")
+	}
+	if first.index != -1 {
+		t.Fatalf("popped job should have index=-1, got %d", first.index)
+	}
+
+	second := heap.Pop(&h).(*job)
+	if second != j1 {
+		t.Fatalf("expected job a second, got %s", second.id)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, baseRetryBackoff},
+		{2, baseRetryBackoff * 2},
+		{3, baseRetryBackoff * 4},
+		{4, maxRetryBackoff}, // would be 16s uncapped
+		{10, maxRetryBackoff},
+	}
+	for _, c := range cases {
+		if got := retryBackoff(c.attempt); got != c.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func newTestQueue() *Queue {
+	return NewQueue(slog.Default(), nil, 1, 3, "")
+}
+
+// TestCancelDuringRetryBackoff reproduces the window between a failed
+// attempt and the job being re-pushed onto the heap: cancel has been
+// cleared but the job hasn't reached the heap yet, so its leftover index is
+// the -1 jobHeap.Pop sentinel. Cancel must not call heap.Remove in that
+// state.
+func TestCancelDuringRetryBackoff(t *testing.T) {
+	q := newTestQueue()
+
+	j := &job{id: "job-1", attempt: 1, maxRetries: 3, index: -1}
+	q.jobs[j.id] = j
+
+	if err := q.Cancel(j.id); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+
+	if _, ok := q.jobs[j.id]; ok {
+		t.Fatal("canceled job should be removed from q.jobs")
+	}
+	if !j.canceled {
+		t.Fatal("canceled job should have canceled=true, so a pending retry won't requeue it")
+	}
+}
+
+func TestCancelWaitingJobRemovesFromHeap(t *testing.T) {
+	q := newTestQueue()
+
+	j := &job{id: "job-1", maxRetries: 3}
+	q.jobs[j.id] = j
+	heap.Push(&q.pq, j)
+
+	if err := q.Cancel(j.id); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if q.pq.Len() != 0 {
+		t.Fatalf("canceled job should be removed from the heap, pq.Len() = %d", q.pq.Len())
+	}
+}
+
+func TestCancelRunningJobEmitsFailedEvent(t *testing.T) {
+	q := newTestQueue()
+
+	canceled := false
+	j := &job{id: "job-1", maxRetries: 3, cancel: func() { canceled = true }}
+	q.jobs[j.id] = j
+
+	sub := q.Subscribe()
+
+	if err := q.Cancel(j.id); err != nil {
+		t.Fatalf("Cancel returned error: %v", err)
+	}
+	if !canceled {
+		t.Fatal("Cancel should invoke the job's context CancelFunc")
+	}
+
+	select {
+	case e := <-sub:
+		if e.Type != EventFailed {
+			t.Fatalf("expected EventFailed, got %s", e.Type)
+		}
+		if e.Err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", e.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cancel did not emit a terminal event for a running job")
+	}
+}
+
+func TestCancelUnknownJobReturnsErrJobNotFound(t *testing.T) {
+	q := newTestQueue()
+	if err := q.Cancel("nope"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound for an unknown job id, got %v", err)
+	}
+}
+
+// TestSubscribeFanOutToAllSubscribers guards against the daemon and the GUI
+// racing over a single shared event channel: every subscriber must see
+// every event, not just one of them.
+func TestSubscribeFanOutToAllSubscribers(t *testing.T) {
+	q := newTestQueue()
+
+	subA := q.Subscribe()
+	subB := q.Subscribe()
+
+	q.emit(Event{JobID: "job-1", Type: EventQueued})
+	q.emit(Event{JobID: "job-1", Type: EventCompleted})
+
+	for _, sub := range []<-chan Event{subA, subB} {
+		for _, wantType := range []EventType{EventQueued, EventCompleted} {
+			select {
+			case e := <-sub:
+				if e.Type != wantType {
+					t.Fatalf("got event %s, want %s", e.Type, wantType)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("subscriber did not receive %s", wantType)
+			}
+		}
+	}
+}
+
+func TestUnsubscribeStopsDelivery(t *testing.T) {
+	q := newTestQueue()
+
+	sub := q.Subscribe()
+	q.Unsubscribe(sub)
+
+	q.emit(Event{JobID: "job-1", Type: EventQueued})
+
+	select {
+	case e := <-sub:
+		t.Fatalf("unsubscribed channel should not receive events, got %v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestEnqueuePersistsStateUnderMissingParentDir reproduces a fresh install:
+// statePath's parent directory doesn't exist yet (nothing has created it),
+// so persistStateLocked must create it itself rather than silently failing
+// to write state on every call.
+func TestEnqueuePersistsStateUnderMissingParentDir(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "transcribo", "queue_state.json")
+
+	q := NewQueue(slog.Default(), nil, 1, 3, statePath)
+
+	if _, err := os.Stat(filepath.Dir(statePath)); err == nil {
+		t.Fatalf("precondition failed: %s already exists", filepath.Dir(statePath))
+	}
+
+	srcPath := filepath.Join(t.TempDir(), "input.mp3")
+	if err := os.WriteFile(srcPath, []byte("fake audio"), 0o644); err != nil {
+		t.Fatalf("writing fake input: %v", err)
+	}
+
+	if _, err := q.EnqueueWithPriority(context.Background(), NewFileInput(srcPath, "input.mp3", "en", "srt"), PriorityNormal); err != nil {
+		t.Fatalf("EnqueueWithPriority returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("queue state was not persisted: %v", err)
+	}
+
+	var persisted []persistedJob
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		t.Fatalf("parsing persisted state: %v", err)
+	}
+	if len(persisted) != 1 || persisted[0].Path != srcPath {
+		t.Fatalf("persisted state = %+v, want one job for %s", persisted, srcPath)
+	}
+}
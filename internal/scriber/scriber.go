@@ -1,63 +1,250 @@
 package scriber
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
-
-	"github.com/alesr/whisperclient"
 )
 
 const sampleRate = "5200"
 
+// encodingWeight and transcribingWeight split the overall job progress
+// between the two stages transcribe reports on: extracting PCM audio with
+// ffmpeg, then uploading it to the transcription backend.
+const (
+	encodingWeight     = 0.4
+	transcribingWeight = 0.6
+)
+
+// chunkThreshold is the probed duration above which transcribe splits the
+// encoded audio into smaller pieces and transcribes them separately,
+// instead of uploading it all in one request. This keeps a single job
+// under Whisper's 25 MB per-request limit regardless of input size.
+const chunkThreshold = 10 * time.Minute
+
+// Input is a media file submitted for transcription.
 type Input interface {
 	Name() string
 	OutputType() string
 	Language() string
+
+	// Model, Prompt, Temperature and Translate are passed straight through
+	// to the transcription backend; implementations with no per-file
+	// override just return the zero value.
+	Model() string
+	Prompt() string
+	Temperature() float64
+	Translate() bool
+
+	// Data must support being called more than once, each call yielding an
+	// independent reader over the same bytes from the start: transcribe
+	// reads it once to probe the duration and again to encode it.
 	Data() io.ReadCloser
 }
 
+// Output is the result of transcribing an Input.
 type Output struct {
 	Name string
 	Text []byte
 }
 
-type whisperClient interface {
-	TranscribeAudio(ctx context.Context, in whisperclient.TranscribeAudioInput) ([]byte, error)
+// Persistable is implemented by Input values that can be reopened from disk
+// by path. The queue uses it to rehydrate jobs that were still queued or
+// in-progress when the process was last stopped; inputs that don't
+// implement it cannot be restored after a restart.
+type Persistable interface {
+	Path() string
 }
 
-type Scriber struct {
-	logger        *slog.Logger
-	whisperClient whisperClient
-	resultsCh     chan Output
+// TranscribeInput is the request transcribe hands to a Transcriber backend.
+// It mirrors the fields Input exposes, plus the encoded audio to upload.
+type TranscribeInput struct {
+	Name        string
+	Language    string
+	Format      string
+	Model       string
+	Prompt      string
+	Temperature float64
+	Translate   bool
+	Data        io.Reader
 }
 
-func New(logger *slog.Logger, whisperCli whisperClient) *Scriber {
-	return &Scriber{
-		logger:        logger.WithGroup("scriber"),
-		whisperClient: whisperCli,
-		resultsCh:     make(chan Output, 10),
+// Transcriber turns encoded audio into text. Implementations: OpenAIWhisper
+// (the hosted OpenAI API this tool originally supported), WhisperCPP (a
+// local whisper.cpp binary, for offline use), and OpenAICompat (any
+// self-hosted OpenAI-compatible server, e.g. faster-whisper).
+type Transcriber interface {
+	TranscribeAudio(ctx context.Context, in TranscribeInput) ([]byte, error)
+}
+
+// reportFunc receives the overall 0..1 fraction complete for a job, along
+// with a short label for the stage currently driving it ("encoding" or
+// "transcribing").
+type reportFunc func(stage string, fraction float64)
+
+// transcribe runs ffmpeg to extract PCM audio from in and sends it to
+// transcriber for transcription, calling report with progress along the
+// way. On the single-request path neither the source nor the encoded audio
+// is buffered in full: ffmpeg reads in.Data() directly and writes to a pipe
+// that's uploaded as it's produced. Inputs longer than chunkThreshold are
+// instead split and transcribed in pieces; splitting holds the whole
+// encoded audio in memory. It's the unit of work a Queue worker retries on
+// failure.
+func transcribe(ctx context.Context, logger *slog.Logger, transcriber Transcriber, in Input, report reportFunc) (Output, error) {
+	logger.Info("Processing file", slog.String("name", in.Name()))
+
+	probeSrc := in.Data()
+	duration, err := probeDuration(ctx, probeSrc)
+	probeSrc.Close()
+	if err != nil {
+		logger.Warn("probing duration, progress won't be available",
+			slog.String("file", in.Name()), slog.Any("error", err))
+	}
+
+	encodeSrc := in.Data()
+	defer encodeSrc.Close()
+
+	logger.Info("Running ffmpeg", slog.String("file", in.Name()))
+	pcm, err := encodeToWAV(ctx, logger, encodeSrc, duration.Microseconds(), func(fraction float64) {
+		report("encoding", clampFraction(fraction)*encodingWeight)
+	})
+	if err != nil {
+		return Output{}, err
 	}
+	defer pcm.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, transcribeTimeout(duration))
+	defer cancel()
+
+	if duration > chunkThreshold {
+		logger.Info("input exceeds chunk threshold, splitting",
+			slog.String("file", in.Name()), slog.Duration("duration", duration))
+		return transcribeChunked(ctx, transcriber, in, pcm, logger, report)
+	}
+
+	logger.Info("Transcribing audio", slog.String("file", in.Name()))
+
+	upload := &progressReader{
+		r:     pcm,
+		total: wavSizeForDuration(duration),
+		onRead: func(fraction float64) {
+			report("transcribing", encodingWeight+clampFraction(fraction)*transcribingWeight)
+		},
+	}
+
+	text, err := transcriber.TranscribeAudio(ctx, TranscribeInput{
+		Name:        in.Name(),
+		Language:    in.Language(),
+		Format:      in.OutputType(),
+		Model:       in.Model(),
+		Prompt:      in.Prompt(),
+		Temperature: in.Temperature(),
+		Translate:   in.Translate(),
+		Data:        upload,
+	})
+	if err != nil {
+		return Output{}, fmt.Errorf("transcription failed: %w", err)
+	}
+
+	logger.Info("Processing complete", slog.String("file", in.Name()))
+
+	return Output{
+		Name: strings.Replace(
+			in.Name(),
+			filepath.Ext(in.Name()),
+			"."+in.OutputType(), 1,
+		), // foo.mp4 -> foo.srt
+		Text: text,
+	}, nil
+}
+
+// transcribeTimeout gives longer jobs proportionally more time: one
+// increment per chunk a job this long would be split into, or one
+// increment flat for anything under chunkThreshold.
+func transcribeTimeout(duration time.Duration) time.Duration {
+	const perChunk = 5 * time.Minute
+	if duration <= chunkThreshold {
+		return perChunk
+	}
+	chunks := time.Duration(duration/chunkWindow) + 1
+	return perChunk * chunks
 }
 
-func (s *Scriber) Process(ctx context.Context, in Input) error {
-	s.logger.Info("Processing file", slog.String("name", in.Name()))
+// probeDuration asks ffprobe for the duration of the media read from r,
+// without buffering it into memory first.
+func probeDuration(ctx context.Context, r io.Reader) (time.Duration, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_format",
+		"-show_streams",
+		"-print_format", "json",
+		"-i", "pipe:0",
+	)
+	cmd.Stdin = r
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &probe); err != nil {
+		return 0, fmt.Errorf("parsing ffprobe output: %w", err)
+	}
 
-	data, err := io.ReadAll(in.Data())
+	seconds, err := strconv.ParseFloat(probe.Format.Duration, 64)
 	if err != nil {
-		return fmt.Errorf("reading input: %w", err)
+		return 0, fmt.Errorf("parsing duration %q: %w", probe.Format.Duration, err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ffmpegOutput is ffmpeg's stdout pipe plus the running command behind it.
+// Close drains whatever the caller left unread, which is what lets ffmpeg
+// finish writing without deadlocking on a full pipe buffer.
+type ffmpegOutput struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+// Close drains any unread output before waiting for ffmpeg to exit: per
+// os/exec, Wait must only be called after all reads from the pipe have
+// completed, and a caller that bailed out early (e.g. a failed upload)
+// otherwise leaves ffmpeg blocked writing to a full pipe forever.
+func (o *ffmpegOutput) Close() error {
+	_, _ = io.Copy(io.Discard, o.ReadCloser)
+	if err := o.cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w", err)
 	}
-	defer in.Data().Close()
+	return nil
+}
 
-	cmd := exec.Command(
-		"ffmpeg", "-y",
+// encodeToWAV starts ffmpeg decoding src into PCM WAV, returning its stdout
+// pipe immediately so the caller can start uploading before encoding
+// finishes rather than buffering the whole result first. It reports a 0..1
+// fraction derived from ffmpeg's -progress output as it goes; durationUs
+// may be zero if the probe failed, in which case progress just isn't
+// reported.
+func encodeToWAV(ctx context.Context, logger *slog.Logger, src io.Reader, durationUs int64, report func(fraction float64)) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y",
 		"-i", "pipe:0",
 		"-vn",
 		"-acodec", "pcm_s16le",
@@ -65,47 +252,108 @@ func (s *Scriber) Process(ctx context.Context, in Input) error {
 		"-ac", "2",
 		"-b:a", "32k",
 		"-f", "wav",
+		"-progress", "pipe:2",
+		"-nostats",
+		"-loglevel", "error",
 		"pipe:1",
 	)
+	cmd.Stdin = src
 
-	cmd.Stdin = bytes.NewReader(data)
-	var outBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("attaching ffmpeg stdout: %w", err)
+	}
 
-	s.logger.Info("Running ffmpeg", slog.String("file", in.Name()))
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg failed: %w", err)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("attaching ffmpeg stderr: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting ffmpeg: %w", err)
+	}
 
-	s.logger.Info("Transcribing audio", slog.String("file", in.Name()))
+	go scanFFmpegProgress(logger, stderr, durationUs, report)
 
-	text, err := s.whisperClient.TranscribeAudio(ctx, whisperclient.TranscribeAudioInput{
-		Name:     in.Name(),
-		Language: in.Language(),
-		Format:   in.OutputType(),
-		Data:     &outBuf,
-	})
-	if err != nil {
-		return fmt.Errorf("transcription failed: %w", err)
+	return &ffmpegOutput{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+// scanFFmpegProgress reads ffmpeg's `-progress pipe:2` key=value lines off
+// r, converting out_time_ms/durationUs into a 0..1 fraction on each
+// "progress=" line.
+func scanFFmpegProgress(logger *slog.Logger, r io.Reader, durationUs int64, report func(fraction float64)) {
+	var outTimeUs int64
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "out_time_ms": // misleadingly named by ffmpeg: this is microseconds
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+				outTimeUs = v
+			}
+		case "progress":
+			if durationUs > 0 {
+				report(float64(outTimeUs) / float64(durationUs))
+			}
+			if value == "end" {
+				return
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.Warn("reading ffmpeg progress", slog.Any("error", err))
 	}
+}
 
-	s.resultsCh <- Output{
-		Name: strings.Replace(
-			in.Name(),
-			filepath.Ext(in.Name()),
-			"."+in.OutputType(), 1,
-		), // foo.mp4 -> foo.srt
-		Text: text,
+// wavSizeForDuration estimates the size in bytes of the PCM WAV encodeToWAV
+// produces for a clip of the given duration, from its fixed sample rate,
+// channel count and bit depth. It's an estimate, not an exact byte count
+// (ffmpeg's actual output may differ slightly), used only to turn bytes
+// uploaded so far into a progress fraction.
+func wavSizeForDuration(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
 	}
+	rate, err := strconv.Atoi(sampleRate)
+	if err != nil {
+		return 0
+	}
+	const bytesPerSample, channels = 2, 2
+	return int64(d.Seconds() * float64(rate) * bytesPerSample * channels)
+}
 
-	s.logger.Info("Processing complete", slog.String("file", in.Name()))
-	return nil
+// progressReader wraps an io.Reader of known total length, invoking
+// onRead with the cumulative fraction consumed after every Read. Passed as
+// the upload body to the transcription backend, it turns bytes read off
+// the wire into progress for the "transcribing" stage.
+type progressReader struct {
+	r      io.Reader
+	total  int64
+	read   int64
+	onRead func(fraction float64)
 }
 
-func (s *Scriber) Collect() <-chan Output {
-	return s.resultsCh
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.total > 0 && p.onRead != nil {
+		p.onRead(float64(p.read) / float64(p.total))
+	}
+	return n, err
+}
+
+func clampFraction(f float64) float64 {
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	default:
+		return f
+	}
 }
@@ -0,0 +1,100 @@
+package scriber
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WhisperCPP shells out to a local whisper.cpp binary (the "main" or
+// "whisper-cli" executable), so transcription works fully offline once a
+// model has been downloaded. BinPath and ModelPath are whatever the caller
+// configured (backendcfg's saved settings); NewWhisperCPP doesn't validate
+// them.
+type WhisperCPP struct {
+	BinPath   string
+	ModelPath string
+}
+
+// NewWhisperCPP returns a Transcriber backed by a local whisper.cpp binary.
+func NewWhisperCPP(binPath, modelPath string) *WhisperCPP {
+	return &WhisperCPP{BinPath: binPath, ModelPath: modelPath}
+}
+
+func (w *WhisperCPP) TranscribeAudio(ctx context.Context, in TranscribeInput) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "transcribo-whispercpp-*.wav")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp input: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, copyErr := io.Copy(tmp, in.Data)
+	if err := tmp.Close(); copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return nil, fmt.Errorf("writing temp input: %w", copyErr)
+	}
+
+	outBase := strings.TrimSuffix(tmp.Name(), filepath.Ext(tmp.Name()))
+	defer os.Remove(outBase + whisperCPPOutputExt(in.Format))
+
+	args := []string{"-m", w.ModelPath, "-f", tmp.Name(), "-of", outBase, "-nt"}
+	if in.Language != "" {
+		args = append(args, "-l", in.Language)
+	}
+	if in.Translate {
+		args = append(args, "-tr")
+	}
+	if in.Prompt != "" {
+		args = append(args, "--prompt", in.Prompt)
+	}
+	args = append(args, whisperCPPOutputFlag(in.Format))
+
+	cmd := exec.CommandContext(ctx, w.BinPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("whisper.cpp: %w: %s", err, stderr.String())
+	}
+
+	text, err := os.ReadFile(outBase + whisperCPPOutputExt(in.Format))
+	if err != nil {
+		return nil, fmt.Errorf("reading whisper.cpp output: %w", err)
+	}
+	return text, nil
+}
+
+// whisperCPPOutputFlag maps a requested response format to the whisper.cpp
+// CLI flag that produces it; formats it doesn't support fall back to plain
+// text.
+func whisperCPPOutputFlag(format string) string {
+	switch format {
+	case "srt":
+		return "-osrt"
+	case "vtt":
+		return "-ovtt"
+	case "json", "verbose_json":
+		return "-oj"
+	default:
+		return "-otxt"
+	}
+}
+
+func whisperCPPOutputExt(format string) string {
+	switch format {
+	case "srt":
+		return ".srt"
+	case "vtt":
+		return ".vtt"
+	case "json", "verbose_json":
+		return ".json"
+	default:
+		return ".txt"
+	}
+}
@@ -0,0 +1,182 @@
+// Package watch notifies callers about newly created media files in a set
+// of directories, debouncing filesystem events until the file's size has
+// stopped changing so a still-copying file isn't picked up half-written.
+package watch
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// stabilizeInterval is how often pending files are re-stat'd to check
+// whether they've stopped growing.
+const stabilizeInterval = 2 * time.Second
+
+// Watcher reports files with an extension in its allow-list once they've
+// appeared under a watched directory and stabilized.
+type Watcher struct {
+	logger  *slog.Logger
+	exts    map[string]struct{}
+	fsw     *fsnotify.Watcher
+	filesCh chan string
+	done    chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]int64 // path -> last observed size
+}
+
+// New creates a Watcher that reports files whose lowercased extension is
+// in exts (e.g. ".mp4").
+func New(logger *slog.Logger, exts map[string]struct{}) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating fsnotify watcher: %w", err)
+	}
+
+	w := &Watcher{
+		logger:  logger.WithGroup("watch"),
+		exts:    exts,
+		fsw:     fsw,
+		filesCh: make(chan string, 16),
+		done:    make(chan struct{}),
+		pending: make(map[string]int64),
+	}
+
+	go w.loop()
+	go w.stabilizeLoop()
+
+	return w, nil
+}
+
+// Add starts watching dir. If recursive is true, every subdirectory of dir
+// is added too.
+func (w *Watcher) Add(dir string, recursive bool) error {
+	if err := w.fsw.Add(dir); err != nil {
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+	if !recursive {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := w.Add(filepath.Join(dir, entry.Name()), true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Files returns the channel of stabilized file paths ready to be
+// transcribed.
+func (w *Watcher) Files() <-chan string {
+	return w.filesCh
+}
+
+// Close stops watching and releases the underlying fsnotify resources.
+func (w *Watcher) Close() error {
+	close(w.done)
+	return w.fsw.Close()
+}
+
+func (w *Watcher) loop() {
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+				continue
+			}
+			w.considerCandidate(event.Name)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("fsnotify error", slog.Any("error", err))
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) considerCandidate(path string) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if _, ok := w.exts[ext]; !ok {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return
+	}
+
+	w.mu.Lock()
+	w.pending[path] = info.Size()
+	w.mu.Unlock()
+}
+
+func (w *Watcher) stabilizeLoop() {
+	ticker := time.NewTicker(stabilizeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.checkStable()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *Watcher) checkStable() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for path, lastSize := range w.pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(w.pending, path)
+			continue
+		}
+		if info.Size() != lastSize {
+			w.pending[path] = info.Size()
+			continue
+		}
+
+		delete(w.pending, path)
+		select {
+		case w.filesCh <- path:
+		default:
+			w.logger.Warn("dropping stabilized file, consumer too slow", slog.String("path", path))
+		}
+	}
+}
+
+// MoveProcessed relocates path into a subdir subdirectory alongside it,
+// creating the subdirectory if needed. Used to keep a watched folder free
+// of files that have already been ingested.
+func MoveProcessed(path, subdir string) error {
+	dest := filepath.Join(filepath.Dir(path), subdir)
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", dest, err)
+	}
+	if err := os.Rename(path, filepath.Join(dest, filepath.Base(path))); err != nil {
+		return fmt.Errorf("moving %s: %w", path, err)
+	}
+	return nil
+}
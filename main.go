@@ -3,42 +3,83 @@ package main
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/alesr/httpclient"
 	"github.com/alesr/transcribo/internal/app"
+	"github.com/alesr/transcribo/internal/backendcfg"
+	"github.com/alesr/transcribo/internal/daemon"
 	"github.com/alesr/transcribo/internal/scriber"
-	"github.com/alesr/whisperclient"
 )
 
-const whisperAIModel string = "whisper-1"
+const (
+	whisperAIModel  string = "whisper-1"
+	queueWorkers           = 3
+	queueMaxRetries        = 3
+)
 
 func main() {
 	logger := slog.Default()
 
-	openAIKey := os.Getenv("OPENAI_API_KEY")
-	if openAIKey == "" {
-		logger.Error("OPENAI_API_KEY is required")
+	transcriber, err := newTranscriber()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	statePath, err := os.UserConfigDir()
+	if err != nil {
+		logger.Error("resolving config dir", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	app.New(logger,
-		scriber.New(
-			logger,
-			whisperclient.New(
-				httpclient.New(
-					httpclient.WithTimeout(10*time.Minute),
-					httpclient.WithDialerTimeout(10*time.Second),
-					httpclient.WithDialerKeepAlive(30*time.Second),
-					httpclient.WithTLSHandshakeTimeout(10*time.Second),
-					httpclient.WithResponseHeaderTimeout(30*time.Second),
-					httpclient.WithIdleConnTimeout(30*time.Second),
-					httpclient.WithMaxIdleConns(25),
-					httpclient.WithForceHTTP2Disabled(),
-				),
-				openAIKey,
-				whisperAIModel,
-			),
-		),
-	).Run()
+	queue := scriber.NewQueue(
+		logger,
+		transcriber,
+		queueWorkers,
+		queueMaxRetries,
+		filepath.Join(statePath, "transcribo", "queue_state.json"),
+	)
+	queue.Start()
+	defer queue.Stop()
+
+	// Serve the same queue over a local socket so transcriboctl can share
+	// it instead of spinning up its own workers.
+	daemonSrv := daemon.NewServer(logger, queue)
+	go func() {
+		if err := daemonSrv.ListenAndServe(daemon.SocketPath()); err != nil {
+			logger.Warn("daemon socket stopped", slog.Any("error", err))
+		}
+	}()
+	defer daemonSrv.Close()
+
+	app.New(logger, queue).Run()
+}
+
+// newTranscriber builds the scriber.Transcriber backend the user last
+// configured via the app's backend settings dialog, defaulting to the
+// hosted OpenAI Whisper API. Only the openai backend requires
+// OPENAI_API_KEY; the others run fully offline or against a self-hosted
+// server.
+func newTranscriber() (scriber.Transcriber, error) {
+	cfgPath, err := backendcfg.Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := backendcfg.Load(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return backendcfg.NewTranscriber(cfg, os.Getenv("OPENAI_API_KEY"), whisperAIModel, httpclient.New(
+		httpclient.WithTimeout(10*time.Minute),
+		httpclient.WithDialerTimeout(10*time.Second),
+		httpclient.WithDialerKeepAlive(30*time.Second),
+		httpclient.WithTLSHandshakeTimeout(10*time.Second),
+		httpclient.WithResponseHeaderTimeout(30*time.Second),
+		httpclient.WithIdleConnTimeout(30*time.Second),
+		httpclient.WithMaxIdleConns(25),
+		httpclient.WithForceHTTP2Disabled(),
+	))
 }